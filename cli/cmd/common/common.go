@@ -23,6 +23,13 @@ const (
 	FORWARD_PORT_TSDB      = 5432
 	FORWARD_PORT_JAEGER    = 16686
 	LISTEN_PORT_JAEGER     = 16686
+	LISTEN_PORT_OTLP_GRPC  = 4317
+	FORWARD_PORT_OTLP_GRPC = 4317
+	LISTEN_PORT_OTLP_HTTP  = 4318
+	FORWARD_PORT_OTLP_HTTP = 4318
+
+	// Promscale serves the Jaeger query API on its HTTP port
+	FORWARD_PORT_JAEGER_QUERY = 9201
 )
 
 var (
@@ -155,3 +162,22 @@ func GetTimescaleDBLabels(releaseName string) map[string]string {
 		"release": releaseName,
 	}
 }
+
+// GetPrometheusPVCName returns the prefix of the Prometheus storage PVCs
+func GetPrometheusPVCName(helmClient helm.Client, releaseName string) (string, error) {
+	name, err := helmClient.ExportValuesFieldFromRelease(releaseName, []string{"kube-prometheus-stack", "fullnameOverride"})
+	if err != nil {
+		return "", err
+	}
+
+	return "prometheus-" + fmt.Sprint(name) + "-prometheus-db", nil
+}
+
+// GetOtelCollectorLabels returns the labels of the default
+// OpenTelemetry collector pods created by the operator
+func GetOtelCollectorLabels(releaseName, namespace string) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/instance":  namespace + "." + releaseName + "-opentelemetry",
+		"app.kubernetes.io/component": "opentelemetry-collector",
+	}
+}
@@ -0,0 +1,88 @@
+package common
+
+import (
+	"github.com/timescale/tobs/cli/pkg/helm"
+	"github.com/timescale/tobs/cli/pkg/otel"
+	"github.com/timescale/tobs/cli/pkg/utils"
+)
+
+// Component is a part of the tobs stack running as pods
+type Component struct {
+	Name      string
+	Namespace string
+	Labels    map[string]string
+	// keys of the helm values which all need to be
+	// true for the component to be deployed
+	EnabledKeys [][]string
+}
+
+// GetComponents returns all the components deployed by a tobs release
+func GetComponents(releaseName, namespace string) []Component {
+	return []Component{
+		{
+			Name:        "timescaledb",
+			Namespace:   namespace,
+			Labels:      GetTimescaleDBLabels(releaseName),
+			EnabledKeys: [][]string{{"timescaledb-single", "enabled"}},
+		},
+		{
+			Name:        "promscale",
+			Namespace:   namespace,
+			Labels:      map[string]string{"release": releaseName, "app": releaseName + "-promscale"},
+			EnabledKeys: [][]string{{"promscale", "enabled"}},
+		},
+		{
+			Name:        "prometheus",
+			Namespace:   namespace,
+			Labels:      PrometheusLabels,
+			EnabledKeys: [][]string{{"kube-prometheus-stack", "enabled"}},
+		},
+		{
+			Name:        "grafana",
+			Namespace:   namespace,
+			Labels:      map[string]string{"app.kubernetes.io/instance": releaseName, "app.kubernetes.io/name": "grafana"},
+			EnabledKeys: [][]string{{"kube-prometheus-stack", "enabled"}, {"kube-prometheus-stack", "grafana", "enabled"}},
+		},
+		{
+			Name:        "promlens",
+			Namespace:   namespace,
+			Labels:      map[string]string{"release": releaseName, "component": "promlens"},
+			EnabledKeys: [][]string{{"promlens", "enabled"}},
+		},
+		{
+			Name:        "opentelemetry-operator",
+			Namespace:   otel.OtelOperatorNamespace,
+			Labels:      map[string]string{"control-plane": "controller-manager"},
+			EnabledKeys: [][]string{{"opentelemetryOperator", "enabled"}},
+		},
+		{
+			Name:        "opentelemetry-collector",
+			Namespace:   namespace,
+			Labels:      GetOtelCollectorLabels(releaseName, namespace),
+			EnabledKeys: [][]string{{"opentelemetryOperator", "enabled"}},
+		},
+		{
+			Name:        "cert-manager",
+			Namespace:   otel.CertManagerNamespace,
+			Labels:      map[string]string{"app.kubernetes.io/instance": "cert-manager"},
+			EnabledKeys: [][]string{{"opentelemetryOperator", "enabled"}},
+		},
+	}
+}
+
+// IsEnabled returns whether the component is enabled in the release values
+func (c Component) IsEnabled(values map[string]interface{}) bool {
+	for _, keys := range c.EnabledKeys {
+		e, err := helm.FetchValue(values, keys)
+		// the key isn't always set when the
+		// sub chart enables the component by default
+		if err != nil {
+			continue
+		}
+		enabled, err := utils.InterfaceToBool(e)
+		if err == nil && !enabled {
+			return false
+		}
+	}
+	return true
+}
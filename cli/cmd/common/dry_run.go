@@ -0,0 +1,52 @@
+package common
+
+import (
+	"fmt"
+
+	"github.com/timescale/tobs/cli/pkg/helm"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// PrintDryRun prints the manifests rendered by a dry-run install/upgrade. If showDiff
+// is set only the per resource difference against the deployed release is printed.
+func PrintDryRun(helmClient helm.Client, rel *release.Release, showDiff bool) error {
+	newManifest := helm.RenderedManifest(rel)
+	if !showDiff {
+		fmt.Println(newManifest)
+		return nil
+	}
+
+	// the first revision of a release means
+	// there is nothing deployed to compare with
+	var oldManifest string
+	if rel.Version > 1 {
+		var err error
+		oldManifest, err = helmClient.GetReleaseManifest(rel.Name)
+		if err != nil {
+			return fmt.Errorf("failed to get the deployed release manifest %w", err)
+		}
+	}
+
+	diffs, err := helm.DiffManifests(oldManifest, newManifest)
+	if err != nil {
+		return err
+	}
+
+	var added, modified, removed int
+	for _, d := range diffs {
+		switch d.Action {
+		case helm.ResourceAdded:
+			added++
+		case helm.ResourceModified:
+			modified++
+		case helm.ResourceRemoved:
+			removed++
+		default:
+			continue
+		}
+		fmt.Printf("%s (%s)\n%s\n", d.ID(), d.Action, d.Diff)
+	}
+
+	fmt.Printf("Dry-run: %d to add, %d to change, %d to remove\n", added, modified, removed)
+	return nil
+}
@@ -0,0 +1,66 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/timescale/tobs/cli/pkg/k8s"
+)
+
+// PortForwardTarget is a component port-forwarded to localhost
+type PortForwardTarget struct {
+	Name string
+	// Scheme of the printed URL, without it only host:port is printed
+	Scheme string
+	// Port to listen from, 0 picks a random free port
+	Port    int
+	Forward func(listenPort int) (*k8s.PortForward, error)
+}
+
+// URL returns the local address of the port-forward
+func (t *PortForwardTarget) URL() string {
+	if t.Scheme == "" {
+		return fmt.Sprintf("localhost:%d", t.Port)
+	}
+	return fmt.Sprintf("%s://localhost:%d", t.Scheme, t.Port)
+}
+
+// RunPortForwards port-forwards the targets, prints their local URLs once all of them
+// are up and keeps them running until tobs is interrupted with SIGINT or SIGTERM
+func RunPortForwards(targets ...*PortForwardTarget) error {
+	s := k8s.NewPortForwardSupervisor()
+	for _, t := range targets {
+		t := t
+		s.Add(t.Name, func() (*k8s.PortForward, error) {
+			pf, err := t.Forward(t.Port)
+			if err != nil {
+				return nil, err
+			}
+			// reconnect on the same port when it was picked randomly
+			t.Port = pf.LocalPort()
+			return pf, nil
+		})
+	}
+	s.OnReady = func() {
+		printPortForwards(os.Stdout, targets)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return s.Run(ctx)
+}
+
+func printPortForwards(w io.Writer, targets []*PortForwardTarget) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Component", "URL"})
+	for _, t := range targets {
+		table.Append([]string{t.Name, t.URL()})
+	}
+	table.Render()
+}
@@ -0,0 +1,71 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	root "github.com/timescale/tobs/cli/cmd"
+	"sigs.k8s.io/yaml"
+)
+
+// TextPrinter is implemented by the results of read commands
+// to print the human readable output.
+type TextPrinter interface {
+	PrintText(w io.Writer) error
+}
+
+// PrintResult prints the result of a read command to stdout
+// in the format requested with the global --output flag.
+func PrintResult(result TextPrinter) error {
+	return FprintResult(os.Stdout, root.OutputFormat, result)
+}
+
+// FprintResult prints the result to w in the provided output format.
+func FprintResult(w io.Writer, format string, result TextPrinter) error {
+	switch format {
+	case root.OutputJSON:
+		b, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal result to json %w", err)
+		}
+		_, err = fmt.Fprintln(w, string(b))
+		return err
+	case root.OutputYAML:
+		b, err := yaml.Marshal(result)
+		if err != nil {
+			return fmt.Errorf("failed to marshal result to yaml %w", err)
+		}
+		_, err = w.Write(b)
+		return err
+	default:
+		return result.PrintText(w)
+	}
+}
+
+// PasswordResult is the result of the get-password commands
+type PasswordResult struct {
+	Password string `json:"password"`
+}
+
+func (p *PasswordResult) PrintText(w io.Writer) error {
+	_, err := fmt.Fprintln(w, p.Password)
+	return err
+}
+
+// FormatBytes formats the size in bytes similar to pg_size_pretty
+func FormatBytes(b int64) string {
+	const unit = 1024
+	if b < 10*unit {
+		return fmt.Sprintf("%d bytes", b)
+	}
+	units := []string{"kB", "MB", "GB", "TB", "PB"}
+	value := float64(b) / unit
+	i := 0
+	for value >= 10*unit && i < len(units)-1 {
+		value /= unit
+		i++
+	}
+	return fmt.Sprintf("%.0f %s", value, units[i])
+}
@@ -0,0 +1,26 @@
+package credentials
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	root "github.com/timescale/tobs/cli/cmd"
+)
+
+// credentialsCmd represents the credentials command
+var credentialsCmd = &cobra.Command{
+	Use:   "credentials",
+	Short: "Subcommand for managing the credentials of the tobs stack",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		err := root.RootCmd.PersistentPreRunE(cmd, args)
+		if err != nil {
+			return fmt.Errorf("could not read global flag: %w", err)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	root.RootCmd.AddCommand(credentialsCmd)
+}
@@ -0,0 +1,325 @@
+package credentials
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+	root "github.com/timescale/tobs/cli/cmd"
+	"github.com/timescale/tobs/cli/cmd/common"
+	"github.com/timescale/tobs/cli/pkg/helm"
+	"github.com/timescale/tobs/cli/pkg/k8s"
+	"github.com/timescale/tobs/cli/pkg/pgconn"
+	"github.com/timescale/tobs/cli/pkg/utils"
+)
+
+// rotateCmd represents the credentials rotate command
+var rotateCmd = &cobra.Command{
+	Use:   "rotate",
+	Short: "Rotates the password of a database user and updates the secrets using it",
+	Long: `Rotates the password of a database user, the superuser of the release by default.
+
+The password is generated unless --password-stdin is set. The database and the
+<release>-credentials, Grafana database and Promscale secrets using the password
+are updated as one unit: if any of them fails the others are rolled back.
+Promscale and Grafana are then restarted to pick up the new password.`,
+	Args: cobra.ExactArgs(0),
+	RunE: rotate,
+}
+
+func init() {
+	credentialsCmd.AddCommand(rotateCmd)
+	rotateCmd.Flags().StringP("user", "U", "", "database user to rotate the password of, defaults to the superuser")
+	rotateCmd.Flags().Bool("password-stdin", false, "read the new password from stdin instead of generating it")
+	rotateCmd.Flags().Bool("no-restart", false, "don't restart Promscale and Grafana after the rotation")
+	rotateCmd.Flags().BoolP("confirm", "y", false, "Confirm the rotation without prompting")
+}
+
+// secretUpdate is the change of some keys of a secret, with
+// the previous values of the keys to roll the change back
+type secretUpdate struct {
+	name     string
+	values   map[string][]byte
+	previous map[string][]byte
+	// component which needs a restart to use the new values
+	restart string
+}
+
+func (u secretUpdate) keys() []string {
+	keys := make([]string, 0, len(u.values))
+	for k := range u.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func rotate(cmd *cobra.Command, args []string) error {
+	user, err := cmd.Flags().GetString("user")
+	if err != nil {
+		return fmt.Errorf("could not rotate credentials: %w", err)
+	}
+
+	passwordStdin, err := cmd.Flags().GetBool("password-stdin")
+	if err != nil {
+		return fmt.Errorf("could not rotate credentials: %w", err)
+	}
+
+	noRestart, err := cmd.Flags().GetBool("no-restart")
+	if err != nil {
+		return fmt.Errorf("could not rotate credentials: %w", err)
+	}
+
+	confirm, err := cmd.Flags().GetBool("confirm")
+	if err != nil {
+		return fmt.Errorf("could not rotate credentials: %w", err)
+	}
+
+	var password string
+	if passwordStdin {
+		password, err = utils.ReadPassword(os.Stdin)
+	} else {
+		password, err = utils.GeneratePassword(32)
+	}
+	if err != nil {
+		return fmt.Errorf("could not rotate credentials: %w", err)
+	}
+
+	d, err := common.GetSuperuserDBDetails(root.Namespace, root.HelmReleaseName)
+	if err != nil {
+		return fmt.Errorf("could not rotate credentials: %w", err)
+	}
+	if user == "" {
+		user = d.User
+	}
+
+	k8sClient := k8s.NewClient()
+	updates, err := planSecretUpdates(k8sClient, d, user, password)
+	if err != nil {
+		return fmt.Errorf("could not rotate credentials: %w", err)
+	}
+
+	fmt.Printf("The password of database user %s will be rotated.\n", user)
+	for _, u := range updates {
+		fmt.Printf("  secret %s: %v\n", u.name, u.keys())
+	}
+	restarts := componentsToRestart(updates)
+	if !noRestart && len(restarts) > 0 {
+		fmt.Printf("Restarting afterwards: %v\n", restarts)
+	}
+	if !confirm {
+		utils.ConfirmAction()
+	}
+
+	pool, err := d.OpenConnectionToDB()
+	if err != nil {
+		return fmt.Errorf("could not rotate credentials: %w", err)
+	}
+	defer pool.Close()
+
+	ctx := context.Background()
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("could not rotate credentials: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	err = pgconn.AlterUserPassword(ctx, tx, user, password)
+	if err != nil {
+		return fmt.Errorf("could not rotate credentials: %w", err)
+	}
+
+	// the password is only committed once all the secrets are updated
+	err = applySecretUpdates(k8sClient, updates)
+	if err != nil {
+		return fmt.Errorf("could not rotate credentials: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		revertSecretUpdates(k8sClient, updates)
+		return fmt.Errorf("could not rotate credentials: %w", err)
+	}
+	fmt.Printf("Rotated the password of database user %s\n", user)
+
+	if len(updates) == 0 && !passwordStdin {
+		fmt.Printf("No secret uses this user, the new password is: %s\n", password)
+	}
+
+	if noRestart {
+		return nil
+	}
+	return restartComponents(k8sClient, restarts)
+}
+
+// planSecretUpdates returns the changes to the secrets which hold the password of the user
+func planSecretUpdates(k8sClient k8s.Client, d *pgconn.DBDetails, user, password string) ([]secretUpdate, error) {
+	var updates []secretUpdate
+
+	tsdb, err := common.IsTimescaleDBEnabled(root.HelmReleaseName, root.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if tsdb && user == d.User {
+		name := root.HelmReleaseName + "-credentials"
+		u, err := planSecretUpdate(k8sClient, name, map[string][]byte{d.SecretKey: []byte(password)})
+		if err != nil {
+			return nil, err
+		}
+		updates = append(updates, u)
+	}
+
+	grafanaSecretName := root.HelmReleaseName + "-grafana-db"
+	exists, err := k8sClient.CheckSecretExists(grafanaSecretName, root.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		grafanaSecret, err := k8sClient.KubeGetSecret(root.Namespace, grafanaSecretName)
+		if err != nil {
+			return nil, fmt.Errorf("could not get secret with name %s: %w", grafanaSecretName, err)
+		}
+		if string(grafanaSecret.Data["GF_DATABASE_USER"]) == user {
+			u, err := planSecretUpdate(k8sClient, grafanaSecretName, map[string][]byte{"GF_DATABASE_PASSWORD": []byte(password)})
+			if err != nil {
+				return nil, err
+			}
+			u.restart = "grafana"
+			updates = append(updates, u)
+		}
+	}
+
+	promscaleSecretName, err := pgconn.GetPromscaleSecretName(root.HelmReleaseName, root.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	promscaleSecret, err := k8sClient.KubeGetSecret(root.Namespace, promscaleSecretName)
+	if err != nil {
+		return nil, fmt.Errorf("could not get secret with name %s: %w", promscaleSecretName, err)
+	}
+
+	var promscaleValues map[string][]byte
+	if uri := string(promscaleSecret.Data["PROMSCALE_DB_URI"]); uri != "" {
+		config, err := pgconn.ParseDBURI(uri)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse db-uri %v", err)
+		}
+		if config.ConnConfig.User == user {
+			updatedURI, err := pgconn.UpdatePasswordInDBURI(uri, password)
+			if err != nil {
+				return nil, fmt.Errorf("failed to update password in db-uri %v", err)
+			}
+			promscaleValues = map[string][]byte{"PROMSCALE_DB_URI": []byte(updatedURI)}
+		}
+	} else {
+		helmClient := helm.NewClient(root.Namespace)
+		defer helmClient.Close()
+		promscaleUser, err := helmClient.ExportValuesFieldFromRelease(root.HelmReleaseName, []string{"promscale", "connection", "user"})
+		if err != nil {
+			return nil, err
+		}
+		if fmt.Sprint(promscaleUser) == user {
+			promscaleValues = map[string][]byte{"PROMSCALE_DB_PASSWORD": []byte(password)}
+		}
+	}
+	if promscaleValues != nil {
+		u, err := planSecretUpdate(k8sClient, promscaleSecretName, promscaleValues)
+		if err != nil {
+			return nil, err
+		}
+		u.restart = "promscale"
+		updates = append(updates, u)
+	}
+
+	return updates, nil
+}
+
+func planSecretUpdate(k8sClient k8s.Client, name string, values map[string][]byte) (secretUpdate, error) {
+	secret, err := k8sClient.KubeGetSecret(root.Namespace, name)
+	if err != nil {
+		return secretUpdate{}, fmt.Errorf("could not get secret with name %s: %w", name, err)
+	}
+
+	previous := make(map[string][]byte, len(values))
+	for k := range values {
+		previous[k] = secret.Data[k]
+	}
+	return secretUpdate{name: name, values: values, previous: previous}, nil
+}
+
+func setSecretKeys(k8sClient k8s.Client, name string, values map[string][]byte) error {
+	secret, err := k8sClient.KubeGetSecret(root.Namespace, name)
+	if err != nil {
+		return fmt.Errorf("could not get secret with name %s: %w", name, err)
+	}
+
+	if secret.Data == nil {
+		secret.Data = make(map[string][]byte)
+	}
+	for k, v := range values {
+		secret.Data[k] = v
+	}
+
+	err = k8sClient.KubeUpdateSecret(root.Namespace, secret)
+	if err != nil {
+		return fmt.Errorf("could not update secret with name %s: %w", name, err)
+	}
+	return nil
+}
+
+// applySecretUpdates updates the secrets, rolling back the
+// already updated ones if any of the updates fails
+func applySecretUpdates(k8sClient k8s.Client, updates []secretUpdate) error {
+	for i, u := range updates {
+		err := setSecretKeys(k8sClient, u.name, u.values)
+		if err != nil {
+			revertSecretUpdates(k8sClient, updates[:i])
+			return err
+		}
+	}
+	return nil
+}
+
+func revertSecretUpdates(k8sClient k8s.Client, updates []secretUpdate) {
+	for _, u := range updates {
+		err := setSecretKeys(k8sClient, u.name, u.previous)
+		if err != nil {
+			// on failure just print the error, to indicate users there is an inconsistency in the secrets
+			fmt.Printf("failed to roll back secret %s: %v\n", u.name, err)
+		}
+	}
+}
+
+func componentsToRestart(updates []secretUpdate) []string {
+	var components []string
+	seen := make(map[string]bool)
+	for _, u := range updates {
+		if u.restart != "" && !seen[u.restart] {
+			seen[u.restart] = true
+			components = append(components, u.restart)
+		}
+	}
+	return components
+}
+
+func restartComponents(k8sClient k8s.Client, names []string) error {
+	for _, c := range common.GetComponents(root.HelmReleaseName, root.Namespace) {
+		for _, name := range names {
+			if c.Name != name {
+				continue
+			}
+			restarted, err := k8sClient.RolloutRestartDeployments(c.Namespace, c.Labels)
+			if err != nil {
+				return fmt.Errorf("password rotated but could not restart %s, restart it manually: %w", name, err)
+			}
+			for _, d := range restarted {
+				fmt.Printf("Restarted deployment %s\n", d)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,397 @@
+package debug
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/spf13/cobra"
+	root "github.com/timescale/tobs/cli/cmd"
+	"github.com/timescale/tobs/cli/cmd/common"
+	"github.com/timescale/tobs/cli/pkg/helm"
+	"github.com/timescale/tobs/cli/pkg/k8s"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// bundleCmd represents the debug bundle command
+var bundleCmd = &cobra.Command{
+	Use:   "bundle",
+	Short: "Collects logs, configuration and settings of the tobs stack into a tar.gz file",
+	Long: `Collects the information needed to troubleshoot the tobs stack into a tar.gz file:
+
+  - logs and description of every pod of the release, with the sensitive env values redacted
+  - the release values with passwords redacted and the chart metadata
+  - names and keys of the secrets, without their values
+  - PVC sizes
+  - Promscale and TimescaleDB settings
+
+Failures to collect any of them are recorded in errors.txt inside the bundle.`,
+	Args: cobra.ExactArgs(0),
+	RunE: bundle,
+}
+
+func init() {
+	debugCmd.AddCommand(bundleCmd)
+	bundleCmd.Flags().StringP("file", "f", "", "Path of the bundle file (default tobs-debug-<release>-<timestamp>.tar.gz)")
+	bundleCmd.Flags().Int64("log-lines", 5000, "Number of most recent log lines to collect per container, 0 collects all")
+}
+
+type bundleWriter struct {
+	tw     *tar.Writer
+	dir    string
+	now    time.Time
+	errors []string
+}
+
+func (b *bundleWriter) add(name string, data []byte) error {
+	err := b.tw.WriteHeader(&tar.Header{
+		Name:    path.Join(b.dir, name),
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: b.now,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = b.tw.Write(data)
+	return err
+}
+
+func (b *bundleWriter) addYaml(name string, v interface{}) error {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return b.add(name, data)
+}
+
+// fail records errors which don't stop the
+// collection of the rest of the bundle
+func (b *bundleWriter) fail(section string, err error) {
+	b.errors = append(b.errors, fmt.Sprintf("%s: %v", section, err))
+}
+
+func bundle(cmd *cobra.Command, args []string) error {
+	file, err := cmd.Flags().GetString("file")
+	if err != nil {
+		return fmt.Errorf("could not create debug bundle: %w", err)
+	}
+
+	logLines, err := cmd.Flags().GetInt64("log-lines")
+	if err != nil {
+		return fmt.Errorf("could not create debug bundle: %w", err)
+	}
+
+	now := time.Now()
+	dir := fmt.Sprintf("tobs-debug-%s-%s", root.HelmReleaseName, now.Format("20060102-150405"))
+	if file == "" {
+		file = dir + ".tar.gz"
+	}
+
+	// the bundle holds logs and the release values, keep it readable by the owner only
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("could not create debug bundle: %w", err)
+	}
+	defer f.Close()
+
+	gw := gzip.NewWriter(f)
+	b := &bundleWriter{tw: tar.NewWriter(gw), dir: dir, now: now}
+
+	k8sClient := k8s.NewClient()
+	helmClient := helm.NewClient(root.Namespace)
+	defer helmClient.Close()
+
+	fmt.Println("Collecting pod logs and descriptions...")
+	err = collectPods(b, k8sClient, logLines)
+	if err == nil {
+		fmt.Println("Collecting helm release...")
+		err = collectRelease(b, helmClient)
+	}
+	if err == nil {
+		fmt.Println("Collecting secrets metadata...")
+		err = collectSecrets(b, k8sClient)
+	}
+	if err == nil {
+		fmt.Println("Collecting PVC sizes...")
+		err = collectVolumes(b, k8sClient, helmClient)
+	}
+	if err == nil {
+		fmt.Println("Collecting database settings...")
+		err = collectDBSettings(b)
+	}
+	if err == nil && len(b.errors) > 0 {
+		err = b.add("errors.txt", []byte(strings.Join(b.errors, "\n")+"\n"))
+	}
+	if err == nil {
+		err = b.tw.Close()
+	}
+	if err == nil {
+		err = gw.Close()
+	}
+	if err != nil {
+		_ = os.Remove(file)
+		return fmt.Errorf("could not write debug bundle: %w", err)
+	}
+
+	if len(b.errors) > 0 {
+		fmt.Printf("Some information could not be collected, see errors.txt in the bundle:\n  %s\n", strings.Join(b.errors, "\n  "))
+	}
+	fmt.Printf("Debug bundle written to %s\n", file)
+	return nil
+}
+
+// podEvent is the subset of an event shown by kubectl describe
+type podEvent struct {
+	Type          string    `json:"type"`
+	Reason        string    `json:"reason"`
+	Message       string    `json:"message"`
+	Count         int32     `json:"count"`
+	LastTimestamp time.Time `json:"lastTimestamp"`
+}
+
+func collectPods(b *bundleWriter, k8sClient k8s.Client, logLines int64) error {
+	pods, err := k8sClient.KubeGetAllPods(root.Namespace, root.HelmReleaseName)
+	if err != nil {
+		b.fail("pods", err)
+		return nil
+	}
+
+	// the same pod can match several of the release label selectors
+	seen := make(map[string]bool)
+	for _, pod := range pods {
+		if seen[pod.Name] {
+			continue
+		}
+		seen[pod.Name] = true
+		podDir := path.Join("pods", pod.Name)
+
+		var events []podEvent
+		e, err := k8sClient.KubeGetPodEvents(pod.Namespace, pod.Name)
+		if err != nil {
+			b.fail("events of pod "+pod.Name, err)
+		}
+		for _, event := range e {
+			events = append(events, podEvent{
+				Type:          event.Type,
+				Reason:        event.Reason,
+				Message:       event.Message,
+				Count:         event.Count,
+				LastTimestamp: event.LastTimestamp.Time,
+			})
+		}
+
+		described := pod.DeepCopy()
+		described.ManagedFields = nil
+		redactPodEnv(described)
+		err = b.addYaml(path.Join(podDir, "describe.yaml"), map[string]interface{}{
+			"pod":    described,
+			"events": events,
+		})
+		if err != nil {
+			return err
+		}
+
+		restarts := make(map[string]int32)
+		for _, s := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+			restarts[s.Name] = s.RestartCount
+		}
+
+		for _, c := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
+			err = collectLogs(b, k8sClient, pod, c.Name, logLines, false)
+			if err != nil {
+				return err
+			}
+			if restarts[c.Name] > 0 {
+				err = collectLogs(b, k8sClient, pod, c.Name, logLines, true)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// redactPodEnv replaces the literal values of sensitive env vars and the
+// passwords of connection URIs in env vars, so the pod spec is safe to share
+func redactPodEnv(pod *corev1.Pod) {
+	for _, containers := range [][]corev1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+		for i := range containers {
+			for j, env := range containers[i].Env {
+				if env.Value == "" {
+					continue
+				}
+				if helm.IsSensitiveKey(env.Name) {
+					containers[i].Env[j].Value = helm.RedactedValue
+				} else {
+					containers[i].Env[j].Value = helm.RedactURIPassword(env.Value)
+				}
+			}
+		}
+	}
+}
+
+func collectLogs(b *bundleWriter, k8sClient k8s.Client, pod corev1.Pod, container string, logLines int64, previous bool) error {
+	opts := &corev1.PodLogOptions{Container: container, Previous: previous}
+	if logLines > 0 {
+		opts.TailLines = &logLines
+	}
+
+	name := container + ".log"
+	if previous {
+		name = container + ".previous.log"
+	}
+
+	logs, err := k8sClient.KubeGetPodLogs(pod.Namespace, pod.Name, opts)
+	if err != nil {
+		b.fail(fmt.Sprintf("logs of pod %s container %s", pod.Name, container), err)
+		return nil
+	}
+	return b.add(path.Join("pods", pod.Name, name), logs)
+}
+
+func collectRelease(b *bundleWriter, helmClient helm.Client) error {
+	values, err := helmClient.GetReleaseValues(root.HelmReleaseName)
+	if err != nil {
+		b.fail("release values", err)
+	} else {
+		err = b.addYaml("helm/values.yaml", helm.RedactValues(values))
+		if err != nil {
+			return err
+		}
+	}
+
+	chart, err := helmClient.GetDeployedChartMetadata(root.HelmReleaseName, root.Namespace)
+	if err != nil {
+		b.fail("chart metadata", err)
+		return nil
+	}
+	return b.addYaml("helm/chart.yaml", chart)
+}
+
+// secretMetadata is a secret without the values
+type secretMetadata struct {
+	Name string   `json:"name"`
+	Type string   `json:"type"`
+	Keys []string `json:"keys"`
+}
+
+func collectSecrets(b *bundleWriter, k8sClient k8s.Client) error {
+	secrets, err := k8sClient.KubeGetAllSecrets(root.Namespace)
+	if err != nil {
+		b.fail("secrets", err)
+		return nil
+	}
+
+	var metadata []secretMetadata
+	for _, s := range secrets.Items {
+		m := secretMetadata{Name: s.Name, Type: string(s.Type)}
+		for k := range s.Data {
+			m.Keys = append(m.Keys, k)
+		}
+		sort.Strings(m.Keys)
+		metadata = append(metadata, m)
+	}
+	return b.addYaml("secrets.yaml", metadata)
+}
+
+func collectVolumes(b *bundleWriter, k8sClient k8s.Client, helmClient helm.Client) error {
+	volumes := make(map[string][]*k8s.PVCData)
+	for _, prefix := range []string{"storage-volume", "wal-volume"} {
+		pvcs, err := k8sClient.GetPVCSizes(root.Namespace, prefix, common.GetTimescaleDBLabels(root.HelmReleaseName))
+		if err != nil {
+			b.fail("PVC sizes of "+prefix, err)
+			continue
+		}
+		volumes[prefix] = pvcs
+	}
+
+	prefix, err := common.GetPrometheusPVCName(helmClient, root.HelmReleaseName)
+	if err != nil {
+		b.fail("prometheus PVC name", err)
+	} else {
+		pvcs, err := k8sClient.GetPVCSizes(root.Namespace, prefix, common.PrometheusLabels)
+		if err != nil {
+			b.fail("PVC sizes of "+prefix, err)
+		} else {
+			volumes[prefix] = pvcs
+		}
+	}
+
+	return b.addYaml("volumes.yaml", volumes)
+}
+
+// settings queries collected from the database, keyed by bundle file name
+var dbSettingsQueries = map[string]string{
+	"database/pg_settings.yaml": "SELECT name, setting, unit, source FROM pg_settings ORDER BY name",
+	"database/extensions.yaml":  "SELECT extname, extversion FROM pg_extension ORDER BY extname",
+	"database/promscale.yaml":   "SELECT key, value FROM _prom_catalog.default ORDER BY key",
+}
+
+func collectDBSettings(b *bundleWriter) error {
+	d, err := common.GetSuperuserDBDetails(root.Namespace, root.HelmReleaseName)
+	if err != nil {
+		b.fail("database", err)
+		return nil
+	}
+
+	pool, err := d.OpenConnectionToDB()
+	if err != nil {
+		b.fail("database", err)
+		return nil
+	}
+	defer pool.Close()
+
+	files := make([]string, 0, len(dbSettingsQueries))
+	for file := range dbSettingsQueries {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		rows, err := queryRows(pool, dbSettingsQueries[file])
+		if err != nil {
+			b.fail(file, err)
+			continue
+		}
+		err = b.addYaml(file, rows)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func queryRows(pool *pgxpool.Pool, query string) ([]map[string]interface{}, error) {
+	rows, err := pool.Query(context.Background(), query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []map[string]interface{}
+	for rows.Next() {
+		values, err := rows.Values()
+		if err != nil {
+			return nil, err
+		}
+		row := make(map[string]interface{}, len(values))
+		for i, f := range rows.FieldDescriptions() {
+			row[string(f.Name)] = values[i]
+		}
+		result = append(result, row)
+	}
+
+	return result, rows.Err()
+}
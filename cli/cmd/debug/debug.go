@@ -0,0 +1,26 @@
+package debug
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	root "github.com/timescale/tobs/cli/cmd"
+)
+
+// debugCmd represents the debug command
+var debugCmd = &cobra.Command{
+	Use:   "debug",
+	Short: "Subcommand for troubleshooting operations",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		err := root.RootCmd.PersistentPreRunE(cmd, args)
+		if err != nil {
+			return fmt.Errorf("could not read global flag: %w", err)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	root.RootCmd.AddCommand(debugCmd)
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/spf13/cobra"
 	root "github.com/timescale/tobs/cli/cmd"
+	"github.com/timescale/tobs/cli/cmd/common"
 	"github.com/timescale/tobs/cli/pkg/k8s"
 )
 
@@ -30,7 +31,5 @@ func grafanaGetPassword(cmd *cobra.Command, args []string) error {
 	}
 
 	pass := secret.Data["admin-password"]
-	fmt.Println(string(pass))
-
-	return nil
+	return common.PrintResult(&common.PasswordResult{Password: string(pass)})
 }
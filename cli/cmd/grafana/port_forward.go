@@ -30,24 +30,20 @@ func grafanaPortForward(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("could not port-forward Grafana: %w", err)
 	}
 
-	if err := PortForwardGrafana(port); err != nil {
-		return err
-	}
-
-	select {}
+	return common.RunPortForwards(&common.PortForwardTarget{Name: "Grafana", Scheme: "http", Port: port, Forward: PortForwardGrafana})
 }
 
-func PortForwardGrafana(listenPort int) error {
+func PortForwardGrafana(listenPort int) (*k8s.PortForward, error) {
 	k8sClient := k8s.NewClient()
 	serviceName, err := k8sClient.KubeGetServiceName(root.Namespace, map[string]string{"app.kubernetes.io/instance": root.HelmReleaseName, "app.kubernetes.io/name": "grafana"})
 	if err != nil {
-		return fmt.Errorf("could not port-forward Grafana: %w", err)
+		return nil, fmt.Errorf("could not port-forward Grafana: %w", err)
 	}
 
-	_, err = k8sClient.KubePortForwardService(root.Namespace, serviceName, listenPort, common.FORWARD_PORT_GRAFANA)
+	pf, err := k8sClient.KubePortForwardService(root.Namespace, serviceName, listenPort, common.FORWARD_PORT_GRAFANA)
 	if err != nil {
-		return fmt.Errorf("could not port-forward Grafana: %w", err)
+		return nil, fmt.Errorf("could not port-forward Grafana: %w", err)
 	}
 
-	return nil
+	return pf, nil
 }
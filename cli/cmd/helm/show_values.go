@@ -2,9 +2,13 @@ package helm
 
 import (
 	"fmt"
+	"io"
+
 	"github.com/spf13/cobra"
 	root "github.com/timescale/tobs/cli/cmd"
+	"github.com/timescale/tobs/cli/cmd/common"
 	"github.com/timescale/tobs/cli/pkg/helm"
+	"sigs.k8s.io/yaml"
 )
 
 // helmShowValuesCmd represents the helm show-values command
@@ -20,6 +24,22 @@ func init() {
 	root.AddRootFlags(helmShowValuesCmd)
 }
 
+// ChartValues is the values.yaml of the helm chart
+type ChartValues struct {
+	raw []byte
+}
+
+// MarshalJSON converts the raw values.yaml so that
+// the values can be printed in json and yaml format
+func (c *ChartValues) MarshalJSON() ([]byte, error) {
+	return yaml.YAMLToJSON(c.raw)
+}
+
+func (c *ChartValues) PrintText(w io.Writer) error {
+	_, err := fmt.Fprintln(w, string(c.raw))
+	return err
+}
+
 func helmShowValues(cmd *cobra.Command, args []string) error {
 	var err error
 
@@ -35,6 +55,5 @@ func helmShowValues(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to get helm values: %w", err)
 	}
 
-	fmt.Println(string(res))
-	return nil
+	return common.PrintResult(&ChartValues{raw: res})
 }
@@ -0,0 +1,119 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	root "github.com/timescale/tobs/cli/cmd"
+	"github.com/timescale/tobs/cli/cmd/common"
+	"github.com/timescale/tobs/cli/pkg/helm"
+)
+
+// historyDiffCmd represents the history diff command
+var historyDiffCmd = &cobra.Command{
+	Use:   "diff <revision> <revision>",
+	Short: "Shows the changes of the user-supplied values between two revisions",
+	Long: `Shows the values added, removed and modified between two revisions of the tobs helm release.
+Only the user-supplied values are compared, not the defaults of the chart. Passwords and other
+secrets are redacted, a changed secret is still listed.`,
+	Example: `  tobs history diff 2 3`,
+	Args:    cobra.ExactArgs(2),
+	RunE:    historyDiff,
+}
+
+func init() {
+	historyCmd.AddCommand(historyDiffCmd)
+}
+
+// ValueChange is a changed value between two revisions
+type ValueChange struct {
+	Path   string      `json:"path"`
+	Action string      `json:"action"`
+	Old    interface{} `json:"old,omitempty"`
+	New    interface{} `json:"new,omitempty"`
+}
+
+// HistoryDiffResult is the result of the history diff command
+type HistoryDiffResult struct {
+	From    int           `json:"from"`
+	To      int           `json:"to"`
+	Changes []ValueChange `json:"changes"`
+}
+
+func (h *HistoryDiffResult) PrintText(w io.Writer) error {
+	if len(h.Changes) == 0 {
+		_, err := fmt.Fprintf(w, "The values of revision %d and %d are the same\n", h.From, h.To)
+		return err
+	}
+
+	fmt.Fprintf(w, "Values changed from revision %d to %d:\n", h.From, h.To)
+	for _, c := range h.Changes {
+		switch c.Action {
+		case helm.ResourceAdded:
+			fmt.Fprintf(w, "+ %s: %s\n", c.Path, formatValue(c.New))
+		case helm.ResourceRemoved:
+			fmt.Fprintf(w, "- %s: %s\n", c.Path, formatValue(c.Old))
+		default:
+			fmt.Fprintf(w, "~ %s: %s -> %s\n", c.Path, formatValue(c.Old), formatValue(c.New))
+		}
+	}
+	return nil
+}
+
+// formatValue prints strings as is and any other value as json
+func formatValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+func historyDiff(cmd *cobra.Command, args []string) error {
+	var revisions [2]int
+	for i, arg := range args {
+		rev, err := strconv.Atoi(arg)
+		if err != nil || rev <= 0 {
+			return fmt.Errorf("could not diff the revisions: %q isn't a revision", arg)
+		}
+		revisions[i] = rev
+	}
+
+	helmClient := helm.NewClient(root.Namespace)
+	defer helmClient.Close()
+
+	history, err := helmClient.History(root.HelmReleaseName)
+	if err != nil {
+		return fmt.Errorf("could not get the release history: %w", err)
+	}
+
+	var values [2]map[string]interface{}
+	for i, rev := range revisions {
+		r, err := findRevision(history, rev)
+		if err != nil {
+			return fmt.Errorf("could not diff the revisions: %w", err)
+		}
+		values[i] = r.Values
+	}
+
+	res := &HistoryDiffResult{From: revisions[0], To: revisions[1], Changes: []ValueChange{}}
+	for _, d := range helm.RedactValueDiffs(helm.DiffValues(values[0], values[1])) {
+		res.Changes = append(res.Changes, ValueChange{Path: d.Path, Action: d.Action, Old: d.Old, New: d.New})
+	}
+	return common.PrintResult(res)
+}
+
+func findRevision(history []helm.ReleaseRevision, revision int) (*helm.ReleaseRevision, error) {
+	for i := range history {
+		if history[i].Revision == revision {
+			return &history[i], nil
+		}
+	}
+	return nil, fmt.Errorf("revision %d isn't in the history of release %s", revision, root.HelmReleaseName)
+}
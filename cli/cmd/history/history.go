@@ -0,0 +1,55 @@
+package history
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	root "github.com/timescale/tobs/cli/cmd"
+	"github.com/timescale/tobs/cli/cmd/common"
+	"github.com/timescale/tobs/cli/pkg/helm"
+)
+
+// historyCmd represents the history command
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Lists the revisions of the tobs helm release",
+	Long: `Lists the revisions of the tobs helm release with their chart version, status and date.
+Use tobs history diff to compare the values of two revisions.`,
+	Args: cobra.ExactArgs(0),
+	RunE: history,
+}
+
+func init() {
+	root.RootCmd.AddCommand(historyCmd)
+}
+
+// HistoryResult is the result of the history command
+type HistoryResult struct {
+	Release   string                 `json:"release"`
+	Revisions []helm.ReleaseRevision `json:"revisions"`
+}
+
+func (h *HistoryResult) PrintText(w io.Writer) error {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Revision", "Updated", "Status", "Chart Version", "App Version", "Description"})
+	for _, r := range h.Revisions {
+		table.Append([]string{strconv.Itoa(r.Revision), r.Updated, r.Status, r.ChartVersion, r.AppVersion, r.Description})
+	}
+	table.Render()
+	return nil
+}
+
+func history(cmd *cobra.Command, args []string) error {
+	helmClient := helm.NewClient(root.Namespace)
+	defer helmClient.Close()
+
+	revisions, err := helmClient.History(root.HelmReleaseName)
+	if err != nil {
+		return fmt.Errorf("could not get the release history: %w", err)
+	}
+
+	return common.PrintResult(&HistoryResult{Release: root.HelmReleaseName, Revisions: revisions})
+}
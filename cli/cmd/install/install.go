@@ -39,17 +39,21 @@ func addInstallUtilitiesFlags(cmd *cobra.Command) {
 	cmd.Flags().BoolP("tracing", "", false, "[DEPRECATED] flag is not functional as tobs is installing tracing support by default")
 	cmd.Flags().StringP("external-timescaledb-uri", "e", "", "Connect to an existing db using the provided URI")
 	cmd.Flags().BoolP("confirm", "y", false, "Confirmation for all user input prompts")
+	cmd.Flags().BoolP("dry-run", "", false, "Render the manifests with the merged values without touching the cluster")
+	cmd.Flags().BoolP("diff", "", false, "On dry-run print a per resource diff against the deployed release instead of the rendered manifests")
 }
 
 type InstallSpec struct {
 	ConfigFile         string
 	Ref                string
 	dbURI              string
-	version            string
+	Version            string
 	enableBackUp       bool
 	enablePrometheusHA bool
 	confirmActions     bool
 	dbPassword         string
+	DryRun             bool
+	ShowDiff           bool
 }
 
 func helmInstall(cmd *cobra.Command, args []string) error {
@@ -72,7 +76,7 @@ func helmInstall(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("could not install The Observability Stack: %w", err)
 	}
-	i.version, err = cmd.Flags().GetString("version")
+	i.Version, err = cmd.Flags().GetString("version")
 	if err != nil {
 		return fmt.Errorf("could not install The Observability Stack: %w", err)
 	}
@@ -84,6 +88,17 @@ func helmInstall(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("could not install The Observability Stack: %w", err)
 	}
+	i.DryRun, err = cmd.Flags().GetBool("dry-run")
+	if err != nil {
+		return fmt.Errorf("could not install The Observability Stack: %w", err)
+	}
+	i.ShowDiff, err = cmd.Flags().GetBool("diff")
+	if err != nil {
+		return fmt.Errorf("could not install The Observability Stack: %w", err)
+	}
+	if i.ShowDiff && !i.DryRun {
+		return fmt.Errorf("could not install The Observability Stack: --diff can only be used with --dry-run")
+	}
 
 	// TODO(paulfantom): Remove deprecated flags post 0.10.0 release
 	if cmd.Flags().Changed("tracing") {
@@ -142,6 +157,7 @@ func (c *InstallSpec) InstallStack() error {
 		CreateNamespace: true,
 		Wait:            true,
 		Timeout:         15 * time.Minute,
+		DryRun:          c.DryRun,
 	}
 
 	if c.ConfigFile != "" {
@@ -160,13 +176,16 @@ func (c *InstallSpec) InstallStack() error {
 	// opentelemetry operator needs cert-manager as a dependency as adding cert-manager isn't good practice and
 	// not recommended by the cert-manager maintainers. We are explicitly creating cert-manager with kubectl
 	// for more details on this refer: https://github.com/jetstack/cert-manager/issues/3616
-	err = otel.CreateCertManager(c.confirmActions)
-	if err != nil {
-		return fmt.Errorf("failed to create cert-manager %v", err)
+	// On dry-run cert-manager isn't rendered as part of the chart so we skip it.
+	if !c.DryRun {
+		err = otel.CreateCertManager(c.confirmActions)
+		if err != nil {
+			return fmt.Errorf("failed to create cert-manager %v", err)
+		}
 	}
 
-	if c.version != "" {
-		helmValuesSpec.Version = c.version
+	if c.Version != "" {
+		helmValuesSpec.Version = c.Version
 	}
 
 	v, err := helmClient.ExportValuesFieldFromChart(c.Ref, c.ConfigFile, []string{"promscale", "openTelemetry", "enabled"})
@@ -191,16 +210,22 @@ func (c *InstallSpec) InstallStack() error {
 
 	// As multiple times we are appending Promscale values the below func
 	// helps us to append by overriding the previous configs field by field
-	promscaleConfig := appendPromscaleValues(enabledOTEL, enableTimescaleDB, c.enablePrometheusHA, c.dbURI, c.dbPassword, c.version)
+	promscaleConfig := appendPromscaleValues(enabledOTEL, enableTimescaleDB, c.enablePrometheusHA, c.dbURI, c.dbPassword, c.Version)
 	helmValues = helmValues + promscaleConfig
 
 	helmValuesSpec.ValuesYaml = helmValues
-	fmt.Println("Installing The Observability Stack, this can take a few minutes")
+	if !c.DryRun {
+		fmt.Println("Installing The Observability Stack, this can take a few minutes")
+	}
 	release, err := helmClient.InstallOrUpgradeChart(context.Background(), &helmValuesSpec)
 	if err != nil {
 		return fmt.Errorf("could not install The Observability Stack: %w", err)
 	}
 
+	if c.DryRun {
+		return common.PrintDryRun(helmClient, release, c.ShowDiff)
+	}
+
 	if release.Info == nil {
 		fmt.Println("failed to install tobs completely, release notes generation failed...")
 		return nil
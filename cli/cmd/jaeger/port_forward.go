@@ -0,0 +1,123 @@
+package jaeger
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	root "github.com/timescale/tobs/cli/cmd"
+	"github.com/timescale/tobs/cli/cmd/common"
+	"github.com/timescale/tobs/cli/pkg/k8s"
+)
+
+// jaegerPortForwardCmd represents the jaeger port-forward command
+var jaegerPortForwardCmd = &cobra.Command{
+	Use:   "port-forward",
+	Short: "Port-forwards the Jaeger query API to localhost",
+	Long: `Port-forwards the Jaeger query API served by Promscale to localhost.
+With --otlp the OTLP gRPC and HTTP receivers of the OpenTelemetry collector are
+forwarded as well, so local applications can send traces into the cluster.`,
+	Args: cobra.ExactArgs(0),
+	RunE: jaegerPortForward,
+}
+
+func init() {
+	jaegerCmd.AddCommand(jaegerPortForwardCmd)
+	jaegerPortForwardCmd.Flags().IntP("port", "p", common.LISTEN_PORT_JAEGER, "Port to listen from for the Jaeger query API")
+	AddOTLPFlags(jaegerPortForwardCmd)
+}
+
+// AddOTLPFlags adds the flags for forwarding the OpenTelemetry collector OTLP receivers
+func AddOTLPFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("otlp", false, "Port-forward the OTLP gRPC and HTTP receivers of the OpenTelemetry collector")
+	cmd.Flags().Int("otlp-grpc-port", common.LISTEN_PORT_OTLP_GRPC, "Port to listen from for OTLP gRPC")
+	cmd.Flags().Int("otlp-http-port", common.LISTEN_PORT_OTLP_HTTP, "Port to listen from for OTLP HTTP")
+}
+
+// OTLPPortForwardTargets returns the OTLP port-forwards when they're enabled with the --otlp flag
+func OTLPPortForwardTargets(cmd *cobra.Command) ([]*common.PortForwardTarget, error) {
+	otlp, err := cmd.Flags().GetBool("otlp")
+	if err != nil {
+		return nil, fmt.Errorf("could not port-forward OTLP: %w", err)
+	}
+	if !otlp {
+		return nil, nil
+	}
+
+	grpcPort, err := cmd.Flags().GetInt("otlp-grpc-port")
+	if err != nil {
+		return nil, fmt.Errorf("could not port-forward OTLP: %w", err)
+	}
+
+	httpPort, err := cmd.Flags().GetInt("otlp-http-port")
+	if err != nil {
+		return nil, fmt.Errorf("could not port-forward OTLP: %w", err)
+	}
+
+	return []*common.PortForwardTarget{
+		{
+			Name: "OTLP gRPC",
+			Port: grpcPort,
+			Forward: func(listenPort int) (*k8s.PortForward, error) {
+				return PortForwardOTLP(listenPort, common.FORWARD_PORT_OTLP_GRPC)
+			},
+		},
+		{
+			Name:   "OTLP HTTP",
+			Scheme: "http",
+			Port:   httpPort,
+			Forward: func(listenPort int) (*k8s.PortForward, error) {
+				return PortForwardOTLP(listenPort, common.FORWARD_PORT_OTLP_HTTP)
+			},
+		},
+	}, nil
+}
+
+func jaegerPortForward(cmd *cobra.Command, args []string) error {
+	var err error
+
+	var port int
+	port, err = cmd.Flags().GetInt("port")
+	if err != nil {
+		return fmt.Errorf("could not port-forward Jaeger: %w", err)
+	}
+
+	otlp, err := OTLPPortForwardTargets(cmd)
+	if err != nil {
+		return err
+	}
+
+	targets := append([]*common.PortForwardTarget{{Name: "Jaeger query API", Scheme: "http", Port: port, Forward: PortForwardJaeger}}, otlp...)
+	return common.RunPortForwards(targets...)
+}
+
+func PortForwardJaeger(listenPort int) (*k8s.PortForward, error) {
+	k8sClient := k8s.NewClient()
+	serviceName, err := k8sClient.KubeGetServiceName(root.Namespace, map[string]string{"release": root.HelmReleaseName, "app": root.HelmReleaseName + "-promscale"})
+	if err != nil {
+		return nil, fmt.Errorf("could not port-forward Jaeger: %w", err)
+	}
+
+	pf, err := k8sClient.KubePortForwardService(root.Namespace, serviceName, listenPort, common.FORWARD_PORT_JAEGER_QUERY)
+	if err != nil {
+		return nil, fmt.Errorf("could not port-forward Jaeger: %w", err)
+	}
+
+	return pf, nil
+}
+
+// PortForwardOTLP forwards to the collector pods directly as the
+// operator creates several services matching the collector labels
+func PortForwardOTLP(listenPort, remotePort int) (*k8s.PortForward, error) {
+	k8sClient := k8s.NewClient()
+	podName, err := k8sClient.KubeGetPodName(root.Namespace, common.GetOtelCollectorLabels(root.HelmReleaseName, root.Namespace))
+	if err != nil {
+		return nil, fmt.Errorf("could not port-forward OTLP: %w", err)
+	}
+
+	pf, err := k8sClient.KubePortForwardPod(root.Namespace, podName, listenPort, remotePort)
+	if err != nil {
+		return nil, fmt.Errorf("could not port-forward OTLP: %w", err)
+	}
+
+	return pf, nil
+}
@@ -0,0 +1,195 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/spf13/cobra"
+	root "github.com/timescale/tobs/cli/cmd"
+	"github.com/timescale/tobs/cli/cmd/common"
+	"github.com/timescale/tobs/cli/pkg/policy"
+	"github.com/timescale/tobs/cli/pkg/utils"
+)
+
+// metricsApplyCmd represents the metrics apply command
+var metricsApplyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Reconciles the retention period and chunk interval of all metrics with a policy file",
+	Long: `Reconciles the retention period and chunk interval of all metrics with a policy file.
+
+The policy file sets the defaults and overrides them for metrics selected
+by name or by a regex matching the whole metric name. Overrides by name take
+precedence over overrides by regex, and among regex overrides the first one wins.
+Metrics without a matching override are reset to the default values.
+
+Example policy:
+
+  default:
+    retentionDays: 90
+    chunkInterval: 8h
+  overrides:
+  - name: node_cpu_seconds_total
+    retentionDays: 30
+  - regex: "go_.*"
+    chunkInterval: 1h
+
+All the changes are applied in a single transaction after printing the plan.`,
+	Args: cobra.ExactArgs(0),
+	RunE: metricsApply,
+}
+
+func init() {
+	metricsCmd.AddCommand(metricsApplyCmd)
+	metricsApplyCmd.Flags().StringP("filename", "f", "", "Policy file to apply")
+	metricsApplyCmd.Flags().Bool("dry-run", false, "Only print the plan without applying it")
+	metricsApplyCmd.Flags().BoolP("confirm", "y", false, "Confirmation flag for applying the plan")
+	_ = metricsApplyCmd.MarkFlagRequired("filename")
+}
+
+func metricsApply(cmd *cobra.Command, args []string) error {
+	file, err := cmd.Flags().GetString("filename")
+	if err != nil {
+		return fmt.Errorf("could not apply metrics policy: %w", err)
+	}
+
+	dryRun, err := cmd.Flags().GetBool("dry-run")
+	if err != nil {
+		return fmt.Errorf("could not apply metrics policy: %w", err)
+	}
+
+	confirm, err := cmd.Flags().GetBool("confirm")
+	if err != nil {
+		return fmt.Errorf("could not apply metrics policy: %w", err)
+	}
+
+	p, err := policy.Load(file)
+	if err != nil {
+		return fmt.Errorf("could not apply metrics policy: %w", err)
+	}
+
+	d, err := common.GetSuperuserDBDetails(root.Namespace, root.HelmReleaseName)
+	if err != nil {
+		return err
+	}
+
+	pool, err := d.OpenConnectionToDB()
+	if err != nil {
+		return fmt.Errorf("could not apply metrics policy: %w", err)
+	}
+	defer pool.Close()
+
+	ctx := context.Background()
+	// the state is read in the same transaction the plan is applied
+	// so the plan can't be based on settings which changed meanwhile
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("could not apply metrics policy: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	state, err := getPolicyState(ctx, tx)
+	if err != nil {
+		return fmt.Errorf("could not apply metrics policy: %w", err)
+	}
+
+	changes := p.Plan(state)
+	if len(changes) == 0 {
+		fmt.Println("Metrics are up to date with the policy, nothing to apply")
+		return nil
+	}
+
+	fmt.Printf("Plan: %d changes\n", len(changes))
+	for _, c := range changes {
+		fmt.Printf("  - %v\n", c)
+	}
+
+	if dryRun {
+		return nil
+	}
+
+	if !confirm {
+		utils.ConfirmAction()
+	}
+
+	for _, c := range changes {
+		err = applyPolicyChange(ctx, tx, c)
+		if err != nil {
+			return fmt.Errorf("could not %v: %w", c, err)
+		}
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("could not apply metrics policy: %w", err)
+	}
+
+	fmt.Printf("Applied %d changes\n", len(changes))
+	return nil
+}
+
+func getPolicyState(ctx context.Context, tx pgx.Tx) (*policy.State, error) {
+	state := &policy.State{}
+	var chunkSecs int64
+	err := tx.QueryRow(ctx,
+		`SELECT EXTRACT(day FROM _prom_catalog.get_default_retention_period())::BIGINT,
+	 EXTRACT(epoch FROM _prom_catalog.get_default_chunk_interval())::BIGINT`).Scan(&state.DefaultRetentionDays, &chunkSecs)
+	if err != nil {
+		return nil, err
+	}
+	state.DefaultChunkInterval = time.Duration(chunkSecs) * time.Second
+
+	rows, err := tx.Query(ctx,
+		`SELECT m.metric_name,
+	 EXTRACT(day FROM _prom_catalog.get_metric_retention_period(m.metric_name))::BIGINT,
+	 m.retention_period IS NOT NULL,
+	 d.interval_length,
+	 NOT m.default_chunk_interval
+	 FROM _prom_catalog.metric m
+	 INNER JOIN _timescaledb_catalog.hypertable h
+	    ON (h.schema_name = 'prom_data' AND h.table_name = m.table_name)
+	 INNER JOIN LATERAL
+	 (SELECT dim.interval_length FROM _timescaledb_catalog.dimension dim WHERE dim.hypertable_id = h.id ORDER BY dim.id LIMIT 1) d
+	    ON (true)`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var m policy.MetricState
+		var microsecs int64
+		err = rows.Scan(&m.Name, &m.RetentionDays, &m.CustomRetention, &microsecs, &m.CustomChunkInterval)
+		if err != nil {
+			return nil, err
+		}
+		m.ChunkInterval = time.Duration(microsecs) * time.Microsecond
+		state.Metrics = append(state.Metrics, m)
+	}
+
+	return state, rows.Err()
+}
+
+func applyPolicyChange(ctx context.Context, tx pgx.Tx, c policy.Change) error {
+	var err error
+	switch c.Action {
+	case policy.SetDefaultRetention:
+		_, err = tx.Exec(ctx, "SELECT prom_api.set_default_retention_period(INTERVAL '1 day' * $1)", c.RetentionDays)
+	case policy.SetDefaultChunkInterval:
+		_, err = tx.Exec(ctx, "SELECT prom_api.set_default_chunk_interval($1::INTERVAL)", c.ChunkInterval)
+	case policy.SetRetention:
+		_, err = tx.Exec(ctx, "SELECT prom_api.set_metric_retention_period('prom_data', $1, INTERVAL '1 day' * $2)", c.Metric, c.RetentionDays)
+	case policy.ResetRetention:
+		_, err = tx.Exec(ctx, "SELECT prom_api.reset_metric_retention_period('prom_data', $1)", c.Metric)
+	case policy.SetChunkInterval:
+		_, err = tx.Exec(ctx, "SELECT prom_api.set_metric_chunk_interval($1, $2::INTERVAL)", c.Metric, c.ChunkInterval)
+	case policy.ResetChunkInterval:
+		_, err = tx.Exec(ctx, "SELECT prom_api.reset_metric_chunk_interval($1)", c.Metric)
+	default:
+		err = fmt.Errorf("unknown policy action %s", c.Action)
+	}
+	return err
+}
@@ -0,0 +1,258 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	root "github.com/timescale/tobs/cli/cmd"
+	"github.com/timescale/tobs/cli/cmd/common"
+)
+
+// metricsCardinalityCmd represents the metrics cardinality command
+var metricsCardinalityCmd = &cobra.Command{
+	Use:   "cardinality",
+	Short: "Reports the metrics, label keys and label values with the most series",
+	Long: `Reports the metrics, label keys and label values with the most series.
+Series marked for deletion are not counted.
+
+With --since only the series with samples in the time window are counted.
+This scans the recent data of every metric so it is slower than the default
+report which counts all the series stored in the catalog.`,
+	Args: cobra.ExactArgs(0),
+	RunE: metricsCardinality,
+}
+
+func init() {
+	metricsCmd.AddCommand(metricsCardinalityCmd)
+	metricsCardinalityCmd.Flags().IntP("top", "t", 10, "Number of metrics, label keys and label values to report")
+	metricsCardinalityCmd.Flags().Duration("since", 0, "Only count series with samples in this time window, e.g. 24h (default all series)")
+}
+
+// SeriesCount is the number of series of a metric, label key or label value
+type SeriesCount struct {
+	Name   string `json:"name"`
+	Series int64  `json:"series"`
+}
+
+// LabelValueCount is the number of series with a label value
+type LabelValueCount struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Series int64  `json:"series"`
+}
+
+// CardinalityResult is the result of the metrics cardinality command
+type CardinalityResult struct {
+	Since       string            `json:"since,omitempty"`
+	Metrics     []SeriesCount     `json:"metrics"`
+	LabelKeys   []SeriesCount     `json:"labelKeys"`
+	LabelValues []LabelValueCount `json:"labelValues"`
+}
+
+func (c *CardinalityResult) PrintText(w io.Writer) error {
+	if c.Since != "" {
+		fmt.Fprintf(w, "Series with samples in the last %v\n\n", c.Since)
+	}
+
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Metric", "Series"})
+	for _, m := range c.Metrics {
+		table.Append([]string{m.Name, strconv.FormatInt(m.Series, 10)})
+	}
+	table.Render()
+	fmt.Fprintln(w)
+
+	table = tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Label Key", "Series"})
+	for _, k := range c.LabelKeys {
+		table.Append([]string{k.Name, strconv.FormatInt(k.Series, 10)})
+	}
+	table.Render()
+	fmt.Fprintln(w)
+
+	table = tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Label Key", "Label Value", "Series"})
+	for _, v := range c.LabelValues {
+		table.Append([]string{v.Key, v.Value, strconv.FormatInt(v.Series, 10)})
+	}
+	table.Render()
+	return nil
+}
+
+func metricsCardinality(cmd *cobra.Command, args []string) error {
+	top, err := cmd.Flags().GetInt("top")
+	if err != nil {
+		return fmt.Errorf("could not get cardinality: %w", err)
+	}
+	if top < 1 {
+		return fmt.Errorf("could not get cardinality: %w", errors.New("top must be at least 1"))
+	}
+
+	since, err := cmd.Flags().GetDuration("since")
+	if err != nil {
+		return fmt.Errorf("could not get cardinality: %w", err)
+	}
+
+	res, err := getCardinality(top, since)
+	if err != nil {
+		return err
+	}
+
+	return common.PrintResult(res)
+}
+
+func getCardinality(top int, since time.Duration) (*CardinalityResult, error) {
+	d, err := common.GetSuperuserDBDetails(root.Namespace, root.HelmReleaseName)
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := d.OpenConnectionToDB()
+	if err != nil {
+		return nil, fmt.Errorf("could not get cardinality: %w", err)
+	}
+	defer pool.Close()
+
+	ctx := context.Background()
+	// the active series are collected into a temporary
+	// table which is dropped with the transaction
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not get cardinality: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	res := &CardinalityResult{}
+	// series marked for deletion aren't counted
+	seriesFilter := "s.delete_epoch IS NULL"
+	if since > 0 {
+		res.Since = since.String()
+		err = collectActiveSeries(ctx, tx, since)
+		if err != nil {
+			return nil, fmt.Errorf("could not get active series: %w", err)
+		}
+		seriesFilter += " AND s.id IN (SELECT id FROM active_series)"
+	}
+
+	res.Metrics, err = querySeriesCounts(ctx, tx,
+		`SELECT m.metric_name, count(*) AS series
+	 FROM _prom_catalog.series s
+	 INNER JOIN _prom_catalog.metric m ON (m.id = s.metric_id)
+	 WHERE `+seriesFilter+`
+	 GROUP BY m.metric_name
+	 ORDER BY series DESC, m.metric_name
+	 LIMIT $1`, top)
+	if err != nil {
+		return nil, fmt.Errorf("could not get metrics cardinality: %w", err)
+	}
+
+	res.LabelKeys, err = querySeriesCounts(ctx, tx,
+		`SELECT l.key, count(*) AS series
+	 FROM _prom_catalog.series s
+	 CROSS JOIN LATERAL unnest(s.labels) AS label_id
+	 INNER JOIN _prom_catalog.label l ON (l.id = label_id)
+	 WHERE l.key <> '__name__' AND `+seriesFilter+`
+	 GROUP BY l.key
+	 ORDER BY series DESC, l.key
+	 LIMIT $1`, top)
+	if err != nil {
+		return nil, fmt.Errorf("could not get label keys cardinality: %w", err)
+	}
+
+	rows, err := tx.Query(ctx,
+		`SELECT l.key, l.value, count(*) AS series
+	 FROM _prom_catalog.series s
+	 CROSS JOIN LATERAL unnest(s.labels) AS label_id
+	 INNER JOIN _prom_catalog.label l ON (l.id = label_id)
+	 WHERE l.key <> '__name__' AND `+seriesFilter+`
+	 GROUP BY l.key, l.value
+	 ORDER BY series DESC, l.key, l.value
+	 LIMIT $1`, top)
+	if err != nil {
+		return nil, fmt.Errorf("could not get label values cardinality: %w", err)
+	}
+	defer rows.Close()
+
+	res.LabelValues = []LabelValueCount{}
+	for rows.Next() {
+		var v LabelValueCount
+		err = rows.Scan(&v.Key, &v.Value, &v.Series)
+		if err != nil {
+			return nil, fmt.Errorf("could not get label values cardinality: %w", err)
+		}
+		res.LabelValues = append(res.LabelValues, v)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not get label values cardinality: %w", err)
+	}
+
+	return res, nil
+}
+
+// collectActiveSeries stores the ids of the series with samples in the time window
+// into the active_series table. Metric views are skipped, their series are
+// stored in the tables of the metrics they're defined on.
+func collectActiveSeries(ctx context.Context, tx pgx.Tx, since time.Duration) error {
+	_, err := tx.Exec(ctx, "CREATE TEMPORARY TABLE active_series (id BIGINT) ON COMMIT DROP")
+	if err != nil {
+		return err
+	}
+
+	rows, err := tx.Query(ctx, "SELECT table_schema, table_name FROM _prom_catalog.metric WHERE NOT is_view ORDER BY table_schema, table_name")
+	if err != nil {
+		return err
+	}
+	var tables []pgx.Identifier
+	for rows.Next() {
+		var schema, table string
+		if err = rows.Scan(&schema, &table); err != nil {
+			rows.Close()
+			return err
+		}
+		tables = append(tables, pgx.Identifier{schema, table})
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for _, table := range tables {
+		_, err = tx.Exec(ctx,
+			"INSERT INTO active_series SELECT DISTINCT series_id FROM "+table.Sanitize()+" WHERE time >= now() - $1::INTERVAL",
+			since)
+		if err != nil {
+			return fmt.Errorf("could not scan %v: %w", table.Sanitize(), err)
+		}
+	}
+
+	return nil
+}
+
+func querySeriesCounts(ctx context.Context, tx pgx.Tx, query string, top int) ([]SeriesCount, error) {
+	rows, err := tx.Query(ctx, query, top)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := []SeriesCount{}
+	for rows.Next() {
+		var c SeriesCount
+		err = rows.Scan(&c.Name, &c.Series)
+		if err != nil {
+			return nil, err
+		}
+		counts = append(counts, c)
+	}
+
+	return counts, rows.Err()
+}
@@ -3,6 +3,7 @@ package metrics
 import (
 	"context"
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -22,23 +23,38 @@ func init() {
 	chunkIntervalCmd.AddCommand(chunkIntervalGetCmd)
 }
 
+// ChunkIntervalResult is the chunk interval of a metric
+type ChunkIntervalResult struct {
+	Metric        string `json:"metric"`
+	ChunkInterval string `json:"chunkInterval"`
+}
+
+func (c *ChunkIntervalResult) PrintText(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "Chunk interval for %v: %v\n", c.Metric, c.ChunkInterval)
+	return err
+}
+
 func chunkIntervalGet(cmd *cobra.Command, args []string) error {
-	var err error
+	res, err := getChunkInterval(args[0])
+	if err != nil {
+		return err
+	}
 
-	metric := args[0]
+	return common.PrintResult(res)
+}
 
+func getChunkInterval(metric string) (*ChunkIntervalResult, error) {
 	d, err := common.GetSuperuserDBDetails(root.Namespace, root.HelmReleaseName)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	pool, err := d.OpenConnectionToDB()
 	if err != nil {
-		return fmt.Errorf("could not get chunk interval for %v: %w", metric, err)
+		return nil, fmt.Errorf("could not get chunk interval for %v: %w", metric, err)
 	}
 	defer pool.Close()
 
-	fmt.Printf("Getting chunk interval of %v\n", metric)
 	var microsecs int64
 	err = pool.QueryRow(context.Background(),
 		`SELECT d.interval_length
@@ -49,11 +65,9 @@ func chunkIntervalGet(cmd *cobra.Command, args []string) error {
 	 WHERE table_name = $1`,
 		metric).Scan(&microsecs)
 	if err != nil {
-		return fmt.Errorf("could not get chunk interval for %v: %w", metric, err)
+		return nil, fmt.Errorf("could not get chunk interval for %v: %w", metric, err)
 	}
 
 	interval := time.Duration(microsecs) * time.Microsecond
-	fmt.Println(interval)
-
-	return nil
+	return &ChunkIntervalResult{Metric: metric, ChunkInterval: interval.String()}, nil
 }
@@ -0,0 +1,15 @@
+package metrics
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// compressionCmd represents the compression command
+var compressionCmd = &cobra.Command{
+	Use:   "compression",
+	Short: "Subcommand for operations that change compression settings",
+}
+
+func init() {
+	metricsCmd.AddCommand(compressionCmd)
+}
@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	root "github.com/timescale/tobs/cli/cmd"
+	"github.com/timescale/tobs/cli/cmd/common"
+)
+
+// compressionGetCmd represents the compression get command
+var compressionGetCmd = &cobra.Command{
+	Use:   "get <metric>",
+	Short: "Gets whether compression is enabled for a specific metric",
+	Args:  cobra.ExactArgs(1),
+	RunE:  compressionGet,
+}
+
+func init() {
+	compressionCmd.AddCommand(compressionGetCmd)
+}
+
+// CompressionResult is the compression setting of a metric
+type CompressionResult struct {
+	Metric             string `json:"metric"`
+	CompressionEnabled bool   `json:"compressionEnabled"`
+}
+
+func (c *CompressionResult) PrintText(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "Compression for %v: %v\n", c.Metric, c.CompressionEnabled)
+	return err
+}
+
+func compressionGet(cmd *cobra.Command, args []string) error {
+	res, err := getCompression(args[0])
+	if err != nil {
+		return err
+	}
+
+	return common.PrintResult(res)
+}
+
+func getCompression(metric string) (*CompressionResult, error) {
+	d, err := common.GetSuperuserDBDetails(root.Namespace, root.HelmReleaseName)
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := d.OpenConnectionToDB()
+	if err != nil {
+		return nil, fmt.Errorf("could not get compression setting for %v: %w", metric, err)
+	}
+	defer pool.Close()
+
+	res := &CompressionResult{Metric: metric}
+	err = pool.QueryRow(context.Background(), "SELECT _prom_catalog.get_metric_compression_setting($1)", metric).Scan(&res.CompressionEnabled)
+	if err != nil {
+		return nil, fmt.Errorf("could not get compression setting for %v: %w", metric, err)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	root "github.com/timescale/tobs/cli/cmd"
+	"github.com/timescale/tobs/cli/cmd/common"
+)
+
+// compressionResetCmd represents the compression reset command
+var compressionResetCmd = &cobra.Command{
+	Use:   "reset <metric>",
+	Short: "Resets compression setting to default for a specific metric",
+	Args:  cobra.ExactArgs(1),
+	RunE:  compressionReset,
+}
+
+func init() {
+	compressionCmd.AddCommand(compressionResetCmd)
+}
+
+func compressionReset(cmd *cobra.Command, args []string) error {
+	var err error
+
+	metric := args[0]
+
+	d, err := common.GetSuperuserDBDetails(root.Namespace, root.HelmReleaseName)
+	if err != nil {
+		return err
+	}
+
+	pool, err := d.OpenConnectionToDB()
+	if err != nil {
+		return fmt.Errorf("could not reset compression for %v: %w", metric, err)
+	}
+	defer pool.Close()
+
+	fmt.Printf("Resetting compression for %v back to default\n", metric)
+	_, err = pool.Exec(context.Background(), "SELECT prom_api.reset_metric_compression_setting($1)", metric)
+	if err != nil {
+		return fmt.Errorf("could not reset compression for %v: %w", metric, err)
+	}
+
+	return nil
+}
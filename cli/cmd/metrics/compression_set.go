@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	root "github.com/timescale/tobs/cli/cmd"
+	"github.com/timescale/tobs/cli/cmd/common"
+)
+
+// compressionSetCmd represents the compression set command
+var compressionSetCmd = &cobra.Command{
+	Use:   "set <metric> <true|false>",
+	Short: "Enables or disables compression for a specific metric",
+	Args:  cobra.ExactArgs(2),
+	RunE:  compressionSet,
+}
+
+func init() {
+	compressionCmd.AddCommand(compressionSetCmd)
+}
+
+func compressionSet(cmd *cobra.Command, args []string) error {
+	var err error
+
+	metric := args[0]
+	compression, err := strconv.ParseBool(args[1])
+	if err != nil {
+		return fmt.Errorf("could not set compression for %v: %w", metric, err)
+	}
+
+	d, err := common.GetSuperuserDBDetails(root.Namespace, root.HelmReleaseName)
+	if err != nil {
+		return err
+	}
+
+	pool, err := d.OpenConnectionToDB()
+	if err != nil {
+		return fmt.Errorf("could not set compression for %v: %w", metric, err)
+	}
+	defer pool.Close()
+
+	fmt.Printf("Setting compression for %v to %v\n", metric, compression)
+	_, err = pool.Exec(context.Background(), "SELECT prom_api.set_metric_compression_setting($1, $2)", metric, compression)
+	if err != nil {
+		return fmt.Errorf("could not set compression for %v: %w", metric, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	root "github.com/timescale/tobs/cli/cmd"
+	"github.com/timescale/tobs/cli/cmd/common"
+)
+
+// compressionSetDefaultCmd represents the compression set-default command
+var compressionSetDefaultCmd = &cobra.Command{
+	Use:   "set-default <true|false>",
+	Short: "Sets whether compression is enabled by default",
+	Args:  cobra.ExactArgs(1),
+	RunE:  compressionSetDefault,
+}
+
+func init() {
+	compressionCmd.AddCommand(compressionSetDefaultCmd)
+}
+
+func compressionSetDefault(cmd *cobra.Command, args []string) error {
+	var err error
+
+	compression, err := strconv.ParseBool(args[0])
+	if err != nil {
+		return fmt.Errorf("could not set default compression: %w", err)
+	}
+
+	d, err := common.GetSuperuserDBDetails(root.Namespace, root.HelmReleaseName)
+	if err != nil {
+		return err
+	}
+
+	pool, err := d.OpenConnectionToDB()
+	if err != nil {
+		return fmt.Errorf("could not set default compression: %w", err)
+	}
+	defer pool.Close()
+
+	fmt.Printf("Setting default compression to %v\n", compression)
+	_, err = pool.Exec(context.Background(), "SELECT prom_api.set_default_compression_setting($1)", compression)
+	if err != nil {
+		return fmt.Errorf("could not set default compression: %w", err)
+	}
+
+	return nil
+}
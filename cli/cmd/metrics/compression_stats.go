@@ -0,0 +1,124 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	root "github.com/timescale/tobs/cli/cmd"
+	"github.com/timescale/tobs/cli/cmd/common"
+)
+
+// compressionStatsCmd represents the compression stats command
+var compressionStatsCmd = &cobra.Command{
+	Use:   "stats [metric...]",
+	Short: "Shows compressed and uncompressed chunk sizes per metric",
+	Long:  "Shows compressed and uncompressed chunk sizes per metric. If no metrics are provided the stats of all metrics are shown.",
+	RunE:  compressionStats,
+}
+
+func init() {
+	compressionCmd.AddCommand(compressionStatsCmd)
+}
+
+// CompressionStats is the compression statistics of a single metric
+type CompressionStats struct {
+	Metric                 string  `json:"metric"`
+	TotalChunks            int64   `json:"totalChunks"`
+	CompressedChunks       int64   `json:"compressedChunks"`
+	BeforeCompressionBytes int64   `json:"beforeCompressionBytes"`
+	AfterCompressionBytes  int64   `json:"afterCompressionBytes"`
+	CompressionRatio       float64 `json:"compressionRatio"`
+}
+
+// CompressionStatsResult is the result of the compression stats command
+type CompressionStatsResult struct {
+	Metrics []CompressionStats `json:"metrics"`
+}
+
+func (c *CompressionStatsResult) PrintText(w io.Writer) error {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Metric", "Chunks", "Compressed Chunks", "Before Compression", "After Compression", "Ratio"})
+	for _, s := range c.Metrics {
+		ratio := "-"
+		if s.CompressionRatio > 0 {
+			ratio = fmt.Sprintf("%.2f", s.CompressionRatio)
+		}
+		table.Append([]string{
+			s.Metric,
+			strconv.FormatInt(s.TotalChunks, 10),
+			strconv.FormatInt(s.CompressedChunks, 10),
+			common.FormatBytes(s.BeforeCompressionBytes),
+			common.FormatBytes(s.AfterCompressionBytes),
+			ratio,
+		})
+	}
+	table.Render()
+	return nil
+}
+
+func compressionStats(cmd *cobra.Command, args []string) error {
+	res, err := getCompressionStats(args)
+	if err != nil {
+		return err
+	}
+
+	return common.PrintResult(res)
+}
+
+func getCompressionStats(metrics []string) (*CompressionStatsResult, error) {
+	d, err := common.GetSuperuserDBDetails(root.Namespace, root.HelmReleaseName)
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := d.OpenConnectionToDB()
+	if err != nil {
+		return nil, fmt.Errorf("could not get compression stats: %w", err)
+	}
+	defer pool.Close()
+
+	if metrics == nil {
+		metrics = []string{}
+	}
+
+	// the byte counts are NULL for metrics without compressed chunks
+	rows, err := pool.Query(context.Background(),
+		`SELECT m.metric_name,
+	 COALESCE(s.total_chunks, 0),
+	 COALESCE(s.number_compressed_chunks, 0),
+	 COALESCE(s.before_compression_total_bytes, 0),
+	 COALESCE(s.after_compression_total_bytes, 0)
+	 FROM _prom_catalog.metric m
+	 INNER JOIN _timescaledb_catalog.hypertable h
+	    ON (h.schema_name = 'prom_data' AND h.table_name = m.table_name)
+	 LEFT JOIN LATERAL hypertable_compression_stats(format('%I.%I', h.schema_name, h.table_name)::regclass) s
+	    ON (true)
+	 WHERE cardinality($1::TEXT[]) = 0 OR m.metric_name = ANY($1::TEXT[])
+	 ORDER BY m.metric_name`, metrics)
+	if err != nil {
+		return nil, fmt.Errorf("could not get compression stats: %w", err)
+	}
+	defer rows.Close()
+
+	res := &CompressionStatsResult{Metrics: []CompressionStats{}}
+	for rows.Next() {
+		var s CompressionStats
+		err = rows.Scan(&s.Metric, &s.TotalChunks, &s.CompressedChunks, &s.BeforeCompressionBytes, &s.AfterCompressionBytes)
+		if err != nil {
+			return nil, fmt.Errorf("could not get compression stats: %w", err)
+		}
+		if s.AfterCompressionBytes > 0 {
+			s.CompressionRatio = float64(s.BeforeCompressionBytes) / float64(s.AfterCompressionBytes)
+		}
+		res.Metrics = append(res.Metrics, s)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not get compression stats: %w", err)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,137 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	root "github.com/timescale/tobs/cli/cmd"
+	"github.com/timescale/tobs/cli/cmd/common"
+	"github.com/timescale/tobs/cli/pkg/labels"
+	"github.com/timescale/tobs/cli/pkg/utils"
+)
+
+// metricsDeleteCmd represents the metrics delete command
+var metricsDeleteCmd = &cobra.Command{
+	Use:   "delete <metric> [label matchers]",
+	Short: "Deletes the series of a metric matching the label matchers",
+	Long: `Deletes the series of a metric matching PromQL style label matchers.
+Dropping the whole metric requires --all instead of label matchers.
+
+Examples:
+  tobs metrics delete http_requests_total '{job="foo",instance=~"bar.*"}'
+  tobs metrics delete http_requests_total --all
+
+The number of matching series is printed before deleting, series already
+marked for deletion are not counted. Use --preview to only count the series
+without deleting them.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: metricsDelete,
+}
+
+func init() {
+	metricsCmd.AddCommand(metricsDeleteCmd)
+	metricsDeleteCmd.Flags().Bool("all", false, "Drop the whole metric instead of the series matching label matchers")
+	metricsDeleteCmd.Flags().Bool("preview", false, "Only count the matching series without deleting them")
+	metricsDeleteCmd.Flags().BoolP("confirm", "y", false, "Confirmation flag for deleting the series")
+}
+
+func metricsDelete(cmd *cobra.Command, args []string) error {
+	metric := args[0]
+
+	var matchers []labels.Matcher
+	if len(args) == 2 {
+		var err error
+		matchers, err = labels.ParseMatchers(args[1])
+		if err != nil {
+			return fmt.Errorf("could not delete series of %v: %w", metric, err)
+		}
+	}
+
+	all, err := cmd.Flags().GetBool("all")
+	if err != nil {
+		return fmt.Errorf("could not delete series of %v: %w", metric, err)
+	}
+	if all && len(matchers) > 0 {
+		return fmt.Errorf("could not delete series of %v: --all cannot be used with label matchers", metric)
+	}
+	if !all && len(matchers) == 0 {
+		return fmt.Errorf("could not delete series of %v: provide label matchers or --all to drop the whole metric", metric)
+	}
+
+	preview, err := cmd.Flags().GetBool("preview")
+	if err != nil {
+		return fmt.Errorf("could not delete series of %v: %w", metric, err)
+	}
+
+	confirm, err := cmd.Flags().GetBool("confirm")
+	if err != nil {
+		return fmt.Errorf("could not delete series of %v: %w", metric, err)
+	}
+
+	d, err := common.GetSuperuserDBDetails(root.Namespace, root.HelmReleaseName)
+	if err != nil {
+		return err
+	}
+
+	pool, err := d.OpenConnectionToDB()
+	if err != nil {
+		return fmt.Errorf("could not delete series of %v: %w", metric, err)
+	}
+	defer pool.Close()
+
+	query := `SELECT COALESCE(array_agg(s.id), '{}')
+	 FROM _prom_catalog.series s
+	 INNER JOIN _prom_catalog.metric m ON (m.id = s.metric_id)
+	 WHERE m.metric_name = $1
+	 AND s.delete_epoch IS NULL`
+	queryArgs := []interface{}{metric}
+	if len(matchers) > 0 {
+		condition, args := labels.SeriesCondition(matchers, 2)
+		query += " AND " + condition
+		queryArgs = append(queryArgs, args...)
+	}
+
+	var seriesIDs []int64
+	err = pool.QueryRow(context.Background(), query, queryArgs...).Scan(&seriesIDs)
+	if err != nil {
+		return fmt.Errorf("could not find series of %v: %w", metric, err)
+	}
+
+	selector := metric
+	if len(matchers) > 0 {
+		m := make([]string, 0, len(matchers))
+		for _, matcher := range matchers {
+			m = append(m, matcher.String())
+		}
+		selector = fmt.Sprintf("%s{%s}", metric, strings.Join(m, ","))
+	}
+
+	fmt.Printf("%d series match %v\n", len(seriesIDs), selector)
+	// a metric is dropped even when all its series are already marked for deletion
+	if preview || len(seriesIDs) == 0 && !all {
+		return nil
+	}
+
+	if !confirm {
+		utils.ConfirmAction()
+	}
+
+	if all {
+		fmt.Printf("Dropping metric %v\n", metric)
+		_, err = pool.Exec(context.Background(), "CALL prom_api.drop_metric($1)", metric)
+		if err != nil {
+			return fmt.Errorf("could not drop metric %v: %w", metric, err)
+		}
+		return nil
+	}
+
+	fmt.Printf("Deleting %d series of %v\n", len(seriesIDs), metric)
+	_, err = pool.Exec(context.Background(), "SELECT prom_api.delete_series($1, $2::BIGINT[])", metric, seriesIDs)
+	if err != nil {
+		return fmt.Errorf("could not delete series of %v: %w", metric, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,165 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	root "github.com/timescale/tobs/cli/cmd"
+	"github.com/timescale/tobs/cli/cmd/common"
+)
+
+// metricsListCmd represents the metrics list command
+var metricsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists all metrics with their retention period, chunk interval, compression, series count and size",
+	Long: `Lists all metrics with their retention period, chunk interval, compression, series count and size.
+The settings set for the metric instead of the defaults are marked as custom.`,
+	Args: cobra.ExactArgs(0),
+	RunE: metricsList,
+}
+
+func init() {
+	metricsCmd.AddCommand(metricsListCmd)
+	metricsListCmd.Flags().StringP("regex", "r", "", "Only list metrics with name matching the provided regular expression")
+	metricsListCmd.Flags().StringP("sort-by", "s", "name", "Sort metrics by name or size")
+}
+
+// order by clauses for the supported --sort-by values
+var metricsListOrderBy = map[string]string{
+	"name": "m.metric_name",
+	"size": "size_bytes DESC, m.metric_name",
+}
+
+// MetricDetails is the storage settings and statistics of a single metric.
+// The Custom fields are set when the metric overrides the default setting.
+type MetricDetails struct {
+	Metric              string `json:"metric"`
+	RetentionPeriod     string `json:"retentionPeriod"`
+	CustomRetention     bool   `json:"customRetention"`
+	ChunkInterval       string `json:"chunkInterval"`
+	CustomChunkInterval bool   `json:"customChunkInterval"`
+	CompressionEnabled  bool   `json:"compressionEnabled"`
+	CustomCompression   bool   `json:"customCompression"`
+	SeriesCount         int64  `json:"seriesCount"`
+	SizeBytes           int64  `json:"sizeBytes"`
+	Size                string `json:"size"`
+}
+
+// MetricsListResult is the result of the metrics list command
+type MetricsListResult struct {
+	Metrics []MetricDetails `json:"metrics"`
+}
+
+func (m *MetricsListResult) PrintText(w io.Writer) error {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Metric", "Retention", "Chunk Interval", "Compression", "Series", "Size"})
+	for _, metric := range m.Metrics {
+		table.Append([]string{
+			metric.Metric,
+			markCustom(metric.RetentionPeriod, metric.CustomRetention),
+			markCustom(metric.ChunkInterval, metric.CustomChunkInterval),
+			markCustom(strconv.FormatBool(metric.CompressionEnabled), metric.CustomCompression),
+			strconv.FormatInt(metric.SeriesCount, 10),
+			metric.Size,
+		})
+	}
+	table.Render()
+	return nil
+}
+
+func markCustom(value string, custom bool) string {
+	if custom {
+		return value + " (custom)"
+	}
+	return value
+}
+
+func metricsList(cmd *cobra.Command, args []string) error {
+	regex, err := cmd.Flags().GetString("regex")
+	if err != nil {
+		return fmt.Errorf("could not list metrics: %w", err)
+	}
+
+	sortBy, err := cmd.Flags().GetString("sort-by")
+	if err != nil {
+		return fmt.Errorf("could not list metrics: %w", err)
+	}
+
+	res, err := listMetrics(regex, sortBy)
+	if err != nil {
+		return err
+	}
+
+	return common.PrintResult(res)
+}
+
+func listMetrics(regex, sortBy string) (*MetricsListResult, error) {
+	orderBy, ok := metricsListOrderBy[sortBy]
+	if !ok {
+		return nil, fmt.Errorf("could not list metrics: unsupported sort-by value %q, supported values are: name, size", sortBy)
+	}
+
+	d, err := common.GetSuperuserDBDetails(root.Namespace, root.HelmReleaseName)
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := d.OpenConnectionToDB()
+	if err != nil {
+		return nil, fmt.Errorf("could not list metrics: %w", err)
+	}
+	defer pool.Close()
+
+	// an empty regex matches all the metrics
+	rows, err := pool.Query(context.Background(),
+		`SELECT m.metric_name,
+	 _prom_catalog.get_metric_retention_period(m.metric_name)::TEXT,
+	 d.interval_length,
+	 COALESCE(_prom_catalog.get_metric_compression_setting(m.metric_name), false),
+	 m.retention_period IS NOT NULL,
+	 NOT m.default_chunk_interval,
+	 NOT m.default_compression,
+	 (SELECT count(*) FROM _prom_catalog.series s WHERE s.metric_id = m.id),
+	 hypertable_size(format('%I.%I', h.schema_name, h.table_name)::regclass) AS size_bytes
+	 FROM _prom_catalog.metric m
+	 INNER JOIN _timescaledb_catalog.hypertable h
+	    ON (h.schema_name = 'prom_data' AND h.table_name = m.table_name)
+	 INNER JOIN LATERAL
+	 (SELECT dim.interval_length FROM _timescaledb_catalog.dimension dim WHERE dim.hypertable_id = h.id ORDER BY dim.id LIMIT 1) d
+	    ON (true)
+	 WHERE m.metric_name ~ $1
+	 ORDER BY `+orderBy,
+		regex)
+	if err != nil {
+		return nil, fmt.Errorf("could not list metrics: %w", err)
+	}
+	defer rows.Close()
+
+	res := &MetricsListResult{Metrics: []MetricDetails{}}
+	for rows.Next() {
+		var m MetricDetails
+		var microsecs int64
+		var sizeBytes *int64
+		err = rows.Scan(&m.Metric, &m.RetentionPeriod, &microsecs, &m.CompressionEnabled,
+			&m.CustomRetention, &m.CustomChunkInterval, &m.CustomCompression, &m.SeriesCount, &sizeBytes)
+		if err != nil {
+			return nil, fmt.Errorf("could not list metrics: %w", err)
+		}
+		m.ChunkInterval = (time.Duration(microsecs) * time.Microsecond).String()
+		if sizeBytes != nil {
+			m.SizeBytes = *sizeBytes
+		}
+		m.Size = common.FormatBytes(m.SizeBytes)
+		res.Metrics = append(res.Metrics, m)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not list metrics: %w", err)
+	}
+
+	return res, nil
+}
@@ -3,6 +3,7 @@ package metrics
 import (
 	"context"
 	"fmt"
+	"io"
 
 	"github.com/spf13/cobra"
 	root "github.com/timescale/tobs/cli/cmd"
@@ -21,30 +22,43 @@ func init() {
 	retentionCmd.AddCommand(retentionGetCmd)
 }
 
+// RetentionResult is the retention period of a metric
+type RetentionResult struct {
+	Metric        string `json:"metric"`
+	RetentionDays int    `json:"retentionDays"`
+}
+
+func (r *RetentionResult) PrintText(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "Retention period for %v: %d days\n", r.Metric, r.RetentionDays)
+	return err
+}
+
 func retentionGet(cmd *cobra.Command, args []string) error {
-	var err error
+	res, err := getRetention(args[0])
+	if err != nil {
+		return err
+	}
 
-	metric := args[0]
+	return common.PrintResult(res)
+}
 
+func getRetention(metric string) (*RetentionResult, error) {
 	d, err := common.GetSuperuserDBDetails(root.Namespace, root.HelmReleaseName)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	pool, err := d.OpenConnectionToDB()
 	if err != nil {
-		return fmt.Errorf("could not get retention period for %v: %w", metric, err)
+		return nil, fmt.Errorf("could not get retention period for %v: %w", metric, err)
 	}
 	defer pool.Close()
 
-	fmt.Printf("Getting retention period for %v\n", metric)
-	var days int
-	err = pool.QueryRow(context.Background(), "SELECT EXTRACT(day FROM _prom_catalog.get_metric_retention_period($1))", metric).Scan(&days)
+	res := &RetentionResult{Metric: metric}
+	err = pool.QueryRow(context.Background(), "SELECT EXTRACT(day FROM _prom_catalog.get_metric_retention_period($1))", metric).Scan(&res.RetentionDays)
 	if err != nil {
-		return fmt.Errorf("could not get retention period for %v: %w", metric, err)
+		return nil, fmt.Errorf("could not get retention period for %v: %w", metric, err)
 	}
 
-	fmt.Println(days, "days")
-
-	return nil
+	return res, nil
 }
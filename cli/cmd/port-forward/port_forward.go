@@ -2,24 +2,49 @@ package port_forward
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
 	root "github.com/timescale/tobs/cli/cmd"
 	"github.com/timescale/tobs/cli/cmd/common"
 	"github.com/timescale/tobs/cli/cmd/grafana"
+	"github.com/timescale/tobs/cli/cmd/jaeger"
 	"github.com/timescale/tobs/cli/cmd/prometheus"
 	"github.com/timescale/tobs/cli/cmd/promlens"
 	"github.com/timescale/tobs/cli/cmd/promscale"
 	"github.com/timescale/tobs/cli/cmd/timescaledb"
+	"github.com/timescale/tobs/cli/pkg/helm"
 	"github.com/timescale/tobs/cli/pkg/k8s"
 )
 
 // portForwardCmd represents the port-forward command
 var portForwardCmd = &cobra.Command{
 	Use:   "port-forward",
-	Short: "Port-forwards TimescaleDB, Promscale, Promlens, Grafana, Prometheus, and Jaeger to localhost",
-	Args:  cobra.ExactArgs(0),
-	RunE:  portForward,
+	Short: "Port-forwards TimescaleDB, Promscale, Promlens, Grafana, Prometheus, and the Jaeger query API to localhost",
+	Long: `Port-forwards TimescaleDB, Promscale, Promlens, Grafana, Prometheus, and the Jaeger query API
+served by Promscale to localhost.
+Components disabled in the release are skipped. A port of 0 picks a random free port,
+the local URLs are printed once all the port-forwards are up.`,
+	Args: cobra.ExactArgs(0),
+	RunE: portForward,
+}
+
+// portForwardComponent is a component selectable with --only and --exclude
+type portForwardComponent struct {
+	// name is used by --only, --exclude and as the port flag
+	name string
+	// component is the name in common.GetComponents which needs to be enabled
+	component string
+	target    common.PortForwardTarget
+}
+
+var portForwardComponents = []portForwardComponent{
+	{name: "timescaledb", component: "timescaledb", target: common.PortForwardTarget{Name: "TimescaleDB", Scheme: "postgres", Forward: timescaledb.PortForwardTimescaleDB}},
+	{name: "grafana", component: "grafana", target: common.PortForwardTarget{Name: "Grafana", Scheme: "http", Forward: grafana.PortForwardGrafana}},
+	{name: "prometheus", component: "prometheus", target: common.PortForwardTarget{Name: "Prometheus", Scheme: "http", Forward: prometheus.PortForwardPrometheus}},
+	{name: "promlens", component: "promlens", target: common.PortForwardTarget{Name: "PromLens", Scheme: "http", Forward: promlens.PortForwardPromlens}},
+	{name: "promscale", component: "promscale", target: common.PortForwardTarget{Name: "Promscale", Scheme: "http", Forward: promscale.PortForwardPromscale}},
+	{name: "jaeger", component: "promscale", target: common.PortForwardTarget{Name: "Jaeger query API", Scheme: "http", Forward: jaeger.PortForwardJaeger}},
 }
 
 func init() {
@@ -29,70 +54,119 @@ func init() {
 	portForwardCmd.Flags().IntP("prometheus", "p", common.LISTEN_PORT_PROM, "Port to listen from for Prometheus")
 	portForwardCmd.Flags().IntP("promscale", "c", common.LISTEN_PORT_PROMSCALE, "Port to listen from for the Promscale")
 	portForwardCmd.Flags().IntP("promlens", "l", common.LISTEN_PORT_PROMLENS, "Port to listen from for PromLens")
-	portForwardCmd.Flags().IntP("jaeger", "j", common.LISTEN_PORT_JAEGER, "Port to listen from for Jaeger")
+	portForwardCmd.Flags().IntP("jaeger", "j", common.LISTEN_PORT_JAEGER, "Port to listen from for the Jaeger query API")
+	portForwardCmd.Flags().StringSlice("only", nil, "Only port-forward these components, comma separated list of "+componentNames())
+	portForwardCmd.Flags().StringSlice("exclude", nil, "Don't port-forward these components, comma separated list of "+componentNames())
+	jaeger.AddOTLPFlags(portForwardCmd)
+}
+
+func componentNames() string {
+	names := make([]string, 0, len(portForwardComponents))
+	for _, c := range portForwardComponents {
+		names = append(names, c.name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// parseComponentSelection returns the set of component names, checking they are known
+func parseComponentSelection(names []string) (map[string]bool, error) {
+	selection := make(map[string]bool, len(names))
+	for _, n := range names {
+		found := false
+		for _, c := range portForwardComponents {
+			if c.name == n {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown component %q, must be one of %s", n, componentNames())
+		}
+		selection[n] = true
+	}
+	return selection, nil
 }
 
 func portForward(cmd *cobra.Command, args []string) error {
 	var err error
 
-	timescaledbPort, err := cmd.Flags().GetInt("timescaledb")
+	onlyNames, err := cmd.Flags().GetStringSlice("only")
 	if err != nil {
 		return fmt.Errorf("could not port-forward: %w", err)
 	}
 
-	grafanaPort, err := cmd.Flags().GetInt("grafana")
+	excludeNames, err := cmd.Flags().GetStringSlice("exclude")
 	if err != nil {
 		return fmt.Errorf("could not port-forward: %w", err)
 	}
 
-	prometheusPort, err := cmd.Flags().GetInt("prometheus")
+	if len(onlyNames) > 0 && len(excludeNames) > 0 {
+		return fmt.Errorf("could not port-forward: --only and --exclude can't be used together")
+	}
+
+	only, err := parseComponentSelection(onlyNames)
 	if err != nil {
 		return fmt.Errorf("could not port-forward: %w", err)
 	}
 
-	promscalePort, err := cmd.Flags().GetInt("promscale")
+	exclude, err := parseComponentSelection(excludeNames)
 	if err != nil {
 		return fmt.Errorf("could not port-forward: %w", err)
 	}
 
-	promlensPort, err := cmd.Flags().GetInt("promlens")
+	helmClient := helm.NewClient(root.Namespace)
+	defer helmClient.Close()
+	values, err := helmClient.GetAllReleaseValues(root.HelmReleaseName)
 	if err != nil {
-		return fmt.Errorf("could not port-forward: %w", err)
+		return fmt.Errorf("could not port-forward: could not get the values of the release %v: %w", root.HelmReleaseName, err)
+	}
+
+	enabled := make(map[string]bool)
+	for _, c := range common.GetComponents(root.HelmReleaseName, root.Namespace) {
+		enabled[c.Name] = c.IsEnabled(values)
 	}
 
-	// Port-forward TimescaleDB
 	// if db-uri exists skip the port-forwarding as it isn't the db within the cluster
 	k8sClient := k8s.NewClient()
 	uri, err := common.GetTimescaleDBURI(k8sClient, root.Namespace, root.HelmReleaseName)
 	if err != nil {
 		return err
 	}
+	if uri != "" {
+		enabled["timescaledb"] = false
+	}
 
-	if uri == "" {
-		if err := timescaledb.PortForwardTimescaleDB(timescaledbPort); err != nil {
-			return err
+	var targets []*common.PortForwardTarget
+	for _, c := range portForwardComponents {
+		if (len(only) > 0 && !only[c.name]) || exclude[c.name] {
+			continue
+		}
+		if !enabled[c.component] {
+			fmt.Printf("Skipping %s as it isn't enabled in the release\n", c.target.Name)
+			continue
 		}
-	}
 
-	// Port-forward Grafana
-	if err := grafana.PortForwardGrafana(grafanaPort); err != nil {
-		return err
+		t := c.target
+		t.Port, err = cmd.Flags().GetInt(c.name)
+		if err != nil {
+			return fmt.Errorf("could not port-forward: %w", err)
+		}
+		targets = append(targets, &t)
 	}
 
-	// Port-forward Prometheus
-	if err := prometheus.PortForwardPrometheus(prometheusPort); err != nil {
+	otlp, err := jaeger.OTLPPortForwardTargets(cmd)
+	if err != nil {
 		return err
 	}
-
-	// Port-forward Promlens
-	if err := promlens.PortForwardPromlens(promlensPort); err != nil {
-		return err
+	if len(otlp) > 0 && !enabled["opentelemetry-collector"] {
+		fmt.Println("Skipping OTLP as the OpenTelemetry collector isn't enabled in the release")
+	} else {
+		targets = append(targets, otlp...)
 	}
 
-	// Port-forward Promscale
-	if err := promscale.PortForwardPromscale(promscalePort); err != nil {
-		return err
+	if len(targets) == 0 {
+		return fmt.Errorf("could not port-forward: no components left to port-forward")
 	}
 
-	select {}
+	return common.RunPortForwards(targets...)
 }
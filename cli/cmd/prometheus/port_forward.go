@@ -31,24 +31,20 @@ func prometheusPortForward(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("could not port-forward Prometheus: %w", err)
 	}
 
-	if err := PortForwardPrometheus(port); err != nil {
-		return err
-	}
-
-	select {}
+	return common.RunPortForwards(&common.PortForwardTarget{Name: "Prometheus", Scheme: "http", Port: port, Forward: PortForwardPrometheus})
 }
 
-func PortForwardPrometheus(listenPort int) error {
+func PortForwardPrometheus(listenPort int) (*k8s.PortForward, error) {
 	k8sClient := k8s.NewClient()
 	serviceName, err := k8sClient.KubeGetServiceName(root.Namespace, map[string]string{"release": root.HelmReleaseName, "app": "kube-prometheus-stack-prometheus"})
 	if err != nil {
-		return fmt.Errorf("could not port-forward Prometheus: %w", err)
+		return nil, fmt.Errorf("could not port-forward Prometheus: %w", err)
 	}
 
-	_, err = k8sClient.KubePortForwardService(root.Namespace, serviceName, listenPort, common.FORWARD_PORT_PROM)
+	pf, err := k8sClient.KubePortForwardService(root.Namespace, serviceName, listenPort, common.FORWARD_PORT_PROM)
 	if err != nil {
-		return fmt.Errorf("could not port-forward Prometheus: %w", err)
+		return nil, fmt.Errorf("could not port-forward Prometheus: %w", err)
 	}
 
-	return nil
+	return pf, nil
 }
@@ -22,19 +22,19 @@ func init() {
 	promlensPortForwardCmd.Flags().IntP("port", "p", common.LISTEN_PORT_PROMLENS, "Port to listen from for promlens")
 }
 
-func PortForwardPromlens(listenPort int) error {
+func PortForwardPromlens(listenPort int) (*k8s.PortForward, error) {
 	k8sClient := k8s.NewClient()
 	serviceNamePromlens, err := k8sClient.KubeGetServiceName(root.Namespace, map[string]string{"release": root.HelmReleaseName, "component": "promlens"})
 	if err != nil {
-		return fmt.Errorf("could not port-forward PromLens: %w", err)
+		return nil, fmt.Errorf("could not port-forward PromLens: %w", err)
 	}
 
-	_, err = k8sClient.KubePortForwardService(root.Namespace, serviceNamePromlens, listenPort, common.FORWARD_PORT_PROMLENS)
+	pf, err := k8sClient.KubePortForwardService(root.Namespace, serviceNamePromlens, listenPort, common.FORWARD_PORT_PROMLENS)
 	if err != nil {
-		return fmt.Errorf("could not port-forward PromLens: %w", err)
+		return nil, fmt.Errorf("could not port-forward PromLens: %w", err)
 	}
 
-	return nil
+	return pf, nil
 }
 
 func promlensPortForward(cmd *cobra.Command, args []string) error {
@@ -46,9 +46,5 @@ func promlensPortForward(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("could not port-forward PromLens: %w", err)
 	}
 
-	if err := PortForwardPromlens(port); err != nil {
-		return err
-	}
-
-	select {}
+	return common.RunPortForwards(&common.PortForwardTarget{Name: "PromLens", Scheme: "http", Port: port, Forward: PortForwardPromlens})
 }
@@ -22,19 +22,19 @@ func init() {
 	promscalePortForwardCmd.Flags().IntP("port", "p", common.LISTEN_PORT_PROMSCALE, "Port to listen for the promscale")
 }
 
-func PortForwardPromscale(listenPort int) error {
+func PortForwardPromscale(listenPort int) (*k8s.PortForward, error) {
 	k8sClient := k8s.NewClient()
 	serviceNamePromscale, err := k8sClient.KubeGetServiceName(root.Namespace, map[string]string{"release": root.HelmReleaseName, "app": root.HelmReleaseName + "-promscale"})
 	if err != nil {
-		return fmt.Errorf("could not port-forward Promscale: %w", err)
+		return nil, fmt.Errorf("could not port-forward Promscale: %w", err)
 	}
 
-	_, err = k8sClient.KubePortForwardService(root.Namespace, serviceNamePromscale, listenPort, common.FORWARD_PORT_PROMSCALE)
+	pf, err := k8sClient.KubePortForwardService(root.Namespace, serviceNamePromscale, listenPort, common.FORWARD_PORT_PROMSCALE)
 	if err != nil {
-		return fmt.Errorf("could not port-forward Promscale: %w", err)
+		return nil, fmt.Errorf("could not port-forward Promscale: %w", err)
 	}
 
-	return nil
+	return pf, nil
 }
 
 func promscalePortForward(cmd *cobra.Command, args []string) error {
@@ -46,9 +46,5 @@ func promscalePortForward(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("could not port-forward Promscale: %w", err)
 	}
 
-	if err := PortForwardPromscale(port); err != nil {
-		return err
-	}
-
-	select {}
+	return common.RunPortForwards(&common.PortForwardTarget{Name: "Promscale", Scheme: "http", Port: port, Forward: PortForwardPromscale})
 }
@@ -0,0 +1,78 @@
+package rollback
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	root "github.com/timescale/tobs/cli/cmd"
+	"github.com/timescale/tobs/cli/pkg/helm"
+	"github.com/timescale/tobs/cli/pkg/utils"
+)
+
+// rollbackCmd represents the rollback command
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "Rolls The Observability Stack back to a previous helm revision",
+	Long: `Rolls the helm release of The Observability Stack back to the previous revision, or to
+the given one. The manifest of the revision is applied as a new revision, which recreates the
+resources deleted by a failed upgrade. CRDs, cert-manager and the OpenTelemetry collector
+changed by upgrade migrations are not rolled back.`,
+	Example: `  tobs rollback --revision 3`,
+	Args:    cobra.ExactArgs(0),
+	RunE:    rollback,
+}
+
+func init() {
+	root.RootCmd.AddCommand(rollbackCmd)
+	rollbackCmd.Flags().IntP("revision", "r", 0, "revision to roll back to, defaults to the previous revision")
+	rollbackCmd.Flags().BoolP("confirm", "y", false, "Confirmation flag for rolling back")
+}
+
+func rollback(cmd *cobra.Command, args []string) error {
+	revision, err := cmd.Flags().GetInt("revision")
+	if err != nil {
+		return fmt.Errorf("could not roll back: %w", err)
+	}
+
+	confirm, err := cmd.Flags().GetBool("confirm")
+	if err != nil {
+		return fmt.Errorf("could not roll back: %w", err)
+	}
+
+	if revision < 0 {
+		return errors.New("could not roll back: --revision must be a positive revision")
+	}
+
+	helmClient := helm.NewClient(root.Namespace)
+	defer helmClient.Close()
+
+	current, err := helmClient.GetReleaseRevision(root.HelmReleaseName)
+	if err != nil {
+		return fmt.Errorf("could not roll back: %w", err)
+	}
+	if revision >= current {
+		return fmt.Errorf("could not roll back: revision %d isn't before the current revision %d", revision, current)
+	}
+
+	if revision == 0 {
+		fmt.Printf("Release %s will be rolled back from revision %d to the previous revision.\n", root.HelmReleaseName, current)
+	} else {
+		fmt.Printf("Release %s will be rolled back from revision %d to revision %d.\n", root.HelmReleaseName, current, revision)
+	}
+	if !confirm {
+		utils.ConfirmAction()
+	}
+
+	err = helmClient.Rollback(root.HelmReleaseName, revision)
+	if err != nil {
+		return fmt.Errorf("could not roll back: %w", err)
+	}
+
+	rolledBack, err := helmClient.GetReleaseRevision(root.HelmReleaseName)
+	if err != nil {
+		return fmt.Errorf("could not get the revision after the rollback: %w", err)
+	}
+	fmt.Printf("Successfully rolled back %s, the rollback is revision %d\n", root.HelmReleaseName, rolledBack)
+	return nil
+}
@@ -12,6 +12,12 @@ import (
 var cfgFile string
 var Namespace string
 var HelmReleaseName string
+var OutputFormat string
+
+const (
+	OutputJSON = "json"
+	OutputYAML = "yaml"
+)
 
 // RootCmd represents the base command when called without any subcommands
 var RootCmd = &cobra.Command{
@@ -35,6 +41,14 @@ components of Observability.`,
 			return fmt.Errorf("could not read global flag: %w", err)
 		}
 
+		OutputFormat, err = cmd.Flags().GetString("output")
+		if err != nil {
+			return fmt.Errorf("could not read global flag: %w", err)
+		}
+		if OutputFormat != "" && OutputFormat != OutputJSON && OutputFormat != OutputYAML {
+			return fmt.Errorf("unsupported output format %q, supported formats are: %s, %s", OutputFormat, OutputJSON, OutputYAML)
+		}
+
 		return nil
 	},
 }
@@ -57,6 +71,7 @@ func init() {
 	RootCmd.Flags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.tobs.yaml)")
 	RootCmd.PersistentFlags().StringP("name", "", "tobs", "Helm release name")
 	RootCmd.PersistentFlags().StringP("namespace", "n", "default", "Kubernetes namespace")
+	RootCmd.PersistentFlags().StringP("output", "o", "", "Output format of read commands, one of: json|yaml (default is human readable text)")
 }
 
 // initConfig reads in config file and ENV variables if set.
@@ -81,6 +96,7 @@ func initConfig() {
 
 	// If a config file is found, read it in.
 	if err := viper.ReadInConfig(); err == nil {
-		fmt.Println("Using config file:", viper.ConfigFileUsed())
+		// print to stderr so it doesn't break the --output of read commands
+		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
 	}
 }
@@ -0,0 +1,215 @@
+package status
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	root "github.com/timescale/tobs/cli/cmd"
+	"github.com/timescale/tobs/cli/cmd/common"
+	"github.com/timescale/tobs/cli/pkg/helm"
+	"github.com/timescale/tobs/cli/pkg/k8s"
+	"helm.sh/helm/v3/pkg/release"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// statusCmd represents the status command
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Shows the health of the tobs stack",
+	Long: `Shows the Helm release status and the pod readiness of every component of the tobs stack.
+Components disabled in the Helm values are skipped. The command exits with a non-zero
+status code when the release or any enabled component is degraded.`,
+	Args: cobra.ExactArgs(0),
+	RunE: status,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		err := root.RootCmd.PersistentPreRunE(cmd, args)
+		if err != nil {
+			return fmt.Errorf("could not read global flag: %w", err)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	root.RootCmd.AddCommand(statusCmd)
+}
+
+// PodStatus is the readiness of a single pod
+type PodStatus struct {
+	Name  string `json:"name"`
+	Phase string `json:"phase"`
+	Ready bool   `json:"ready"`
+	Role  string `json:"role,omitempty"`
+}
+
+// ComponentStatus is the health of a component of the stack
+type ComponentStatus struct {
+	Name      string      `json:"name"`
+	Namespace string      `json:"namespace"`
+	Enabled   bool        `json:"enabled"`
+	Healthy   bool        `json:"healthy"`
+	Message   string      `json:"message,omitempty"`
+	Pods      []PodStatus `json:"pods,omitempty"`
+}
+
+// ReleaseStatus is the status of the tobs helm release
+type ReleaseStatus struct {
+	Name         string `json:"name"`
+	Namespace    string `json:"namespace"`
+	ChartVersion string `json:"chartVersion,omitempty"`
+	Status       string `json:"status"`
+	Updated      string `json:"updated,omitempty"`
+	Healthy      bool   `json:"healthy"`
+}
+
+// StatusResult is the result of the status command
+type StatusResult struct {
+	Healthy    bool              `json:"healthy"`
+	Release    ReleaseStatus     `json:"release"`
+	Components []ComponentStatus `json:"components"`
+}
+
+func (s *StatusResult) PrintText(w io.Writer) error {
+	fmt.Fprintf(w, "Release %v in namespace %v: %v", s.Release.Name, s.Release.Namespace, s.Release.Status)
+	if s.Release.ChartVersion != "" {
+		fmt.Fprintf(w, " (chart version %v, updated %v)", s.Release.ChartVersion, s.Release.Updated)
+	}
+	fmt.Fprintln(w)
+
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Component", "Namespace", "Ready", "Status", "Details"})
+	for _, c := range s.Components {
+		if !c.Enabled {
+			table.Append([]string{c.Name, c.Namespace, "-", "disabled", ""})
+			continue
+		}
+
+		ready := 0
+		var details []string
+		for _, p := range c.Pods {
+			if p.Ready {
+				ready++
+			}
+			if p.Role != "" {
+				details = append(details, p.Name+"="+p.Role)
+			}
+		}
+		if c.Message != "" {
+			details = append(details, c.Message)
+		}
+
+		state := "healthy"
+		if !c.Healthy {
+			state = "degraded"
+		}
+		table.Append([]string{c.Name, c.Namespace, fmt.Sprintf("%d/%d", ready, len(c.Pods)), state, strings.Join(details, ", ")})
+	}
+	table.Render()
+
+	if s.Healthy {
+		fmt.Fprintln(w, "tobs stack is healthy")
+	} else {
+		fmt.Fprintln(w, "tobs stack is degraded")
+	}
+	return nil
+}
+
+func status(cmd *cobra.Command, args []string) error {
+	helmClient := helm.NewClient(root.Namespace)
+	defer helmClient.Close()
+
+	res := &StatusResult{
+		Release: ReleaseStatus{Name: root.HelmReleaseName, Namespace: root.Namespace},
+	}
+
+	deployed, err := helmClient.GetDeployedChartMetadata(root.HelmReleaseName, root.Namespace)
+	if err != nil {
+		return fmt.Errorf("could not get the status of the release %v: %w", root.HelmReleaseName, err)
+	}
+	res.Release.ChartVersion = deployed.Version
+	res.Release.Status = deployed.Status
+	res.Release.Updated = deployed.Updated
+	res.Release.Healthy = deployed.Status == release.StatusDeployed.String()
+
+	values, err := helmClient.GetAllReleaseValues(root.HelmReleaseName)
+	if err != nil {
+		return fmt.Errorf("could not get the values of the release %v: %w", root.HelmReleaseName, err)
+	}
+
+	k8sClient := k8s.NewClient()
+	res.Healthy = res.Release.Healthy
+	for _, c := range common.GetComponents(root.HelmReleaseName, root.Namespace) {
+		s := getComponentStatus(k8sClient, c, values)
+		res.Components = append(res.Components, s)
+		if s.Enabled && !s.Healthy {
+			res.Healthy = false
+		}
+	}
+
+	err = common.PrintResult(res)
+	if err != nil {
+		return err
+	}
+
+	if !res.Healthy {
+		cmd.SilenceUsage = true
+		return errors.New("tobs stack is degraded")
+	}
+	return nil
+}
+
+func getComponentStatus(k8sClient k8s.Client, c common.Component, values map[string]interface{}) ComponentStatus {
+	s := ComponentStatus{Name: c.Name, Namespace: c.Namespace, Enabled: c.IsEnabled(values)}
+	if !s.Enabled {
+		return s
+	}
+
+	pods, err := k8sClient.KubeGetPods(c.Namespace, c.Labels)
+	if err != nil {
+		s.Message = err.Error()
+		return s
+	}
+	if len(pods) == 0 {
+		s.Message = "no pods found"
+		return s
+	}
+
+	s.Healthy = true
+	masters := 0
+	for _, pod := range pods {
+		p := PodStatus{Name: pod.Name, Phase: string(pod.Status.Phase), Ready: isPodReady(pod)}
+		if !p.Ready {
+			s.Healthy = false
+		}
+		// patroni labels the timescaledb pods with their replication role
+		if role, ok := pod.Labels["role"]; ok && c.Name == "timescaledb" {
+			p.Role = role
+			if role == "master" && p.Ready {
+				masters++
+			}
+		}
+		s.Pods = append(s.Pods, p)
+	}
+
+	if c.Name == "timescaledb" && masters != 1 {
+		s.Healthy = false
+		s.Message = strconv.Itoa(masters) + " ready master pods, expected 1"
+	}
+
+	return s
+}
+
+func isPodReady(pod corev1.Pod) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
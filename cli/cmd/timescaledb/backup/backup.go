@@ -0,0 +1,68 @@
+package backup
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	root "github.com/timescale/tobs/cli/cmd"
+	"github.com/timescale/tobs/cli/cmd/common"
+	"github.com/timescale/tobs/cli/cmd/timescaledb"
+	"github.com/timescale/tobs/cli/pkg/helm"
+	"github.com/timescale/tobs/cli/pkg/k8s"
+	"github.com/timescale/tobs/cli/pkg/pgbackrest"
+	"github.com/timescale/tobs/cli/pkg/utils"
+)
+
+// stanza configured by the timescaledb-single chart
+const stanza = "poddb"
+
+// backupCmd represents the timescaledb backup command
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Subcommand for TimescaleDB pgBackRest backups",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		err := root.RootCmd.PersistentPreRunE(cmd, args)
+		if err != nil {
+			return fmt.Errorf("could not read global flag: %w", err)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	timescaledb.Cmd.AddCommand(backupCmd)
+}
+
+// getMasterPod returns the master pod after checking backups are enabled in the release
+func getMasterPod(k8sClient k8s.Client) (string, error) {
+	helmClient := helm.NewClient(root.Namespace)
+	defer helmClient.Close()
+	e, err := helmClient.ExportValuesFieldFromRelease(root.HelmReleaseName, common.TimescaleDBBackUpKeyForValuesYaml)
+	if err != nil {
+		return "", err
+	}
+	enabled, err := utils.InterfaceToBool(e)
+	if err != nil {
+		return "", fmt.Errorf("%s was not a bool", strings.Join(common.TimescaleDBBackUpKeyForValuesYaml, "."))
+	}
+	if !enabled {
+		return "", fmt.Errorf("backups are not enabled in release %s, enable them with %s", root.HelmReleaseName, strings.Join(common.TimescaleDBBackUpKeyForValuesYaml, "."))
+	}
+
+	return k8sClient.KubeGetPodName(root.Namespace, map[string]string{"release": root.HelmReleaseName, "role": "master"})
+}
+
+func getStanzaInfo(k8sClient k8s.Client, pod string) (*pgbackrest.Stanza, error) {
+	out, err := timescaledb.ExecOutput(k8sClient, pod, "pgbackrest --stanza="+stanza+" --output=json info")
+	if err != nil {
+		return nil, err
+	}
+
+	stanzas, err := pgbackrest.ParseInfo(out)
+	if err != nil {
+		return nil, err
+	}
+	return pgbackrest.GetStanza(stanzas, stanza)
+}
@@ -0,0 +1,55 @@
+package backup
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	root "github.com/timescale/tobs/cli/cmd"
+	"github.com/timescale/tobs/cli/cmd/timescaledb"
+	"github.com/timescale/tobs/cli/pkg/k8s"
+)
+
+// backupCreateCmd represents the timescaledb backup create command
+var backupCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Creates a pgBackRest backup of TimescaleDB",
+	Args:  cobra.ExactArgs(0),
+	RunE:  backupCreate,
+}
+
+func init() {
+	backupCmd.AddCommand(backupCreateCmd)
+	backupCreateCmd.Flags().StringP("type", "t", "incr", "type of the backup, one of: full|diff|incr")
+}
+
+func backupCreate(cmd *cobra.Command, args []string) error {
+	backupType, err := cmd.Flags().GetString("type")
+	if err != nil {
+		return fmt.Errorf("could not create backup: %w", err)
+	}
+	if backupType != "full" && backupType != "diff" && backupType != "incr" {
+		return fmt.Errorf("could not create backup: unknown type %q, must be one of: full|diff|incr", backupType)
+	}
+
+	k8sClient := k8s.NewClient()
+	pod, err := getMasterPod(k8sClient)
+	if err != nil {
+		return fmt.Errorf("could not create backup: %w", err)
+	}
+
+	fmt.Printf("Creating %s backup in pod %s...\n", backupType, pod)
+	err = k8sClient.KubeExecCmdWithStreams(root.Namespace, pod, timescaledb.Container, "pgbackrest --stanza="+stanza+" --type="+backupType+" --log-level-console=info backup", nil, os.Stdout, os.Stderr)
+	if err != nil {
+		return fmt.Errorf("could not create backup: %w", err)
+	}
+
+	s, err := getStanzaInfo(k8sClient, pod)
+	if err != nil {
+		return fmt.Errorf("could not get the created backup: %w", err)
+	}
+	if len(s.Backups) > 0 {
+		fmt.Printf("Created backup %s\n", s.Backups[len(s.Backups)-1].Label)
+	}
+	return nil
+}
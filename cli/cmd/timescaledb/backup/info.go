@@ -0,0 +1,134 @@
+package backup
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"github.com/timescale/tobs/cli/cmd/common"
+	"github.com/timescale/tobs/cli/pkg/k8s"
+)
+
+// backupInfoCmd represents the timescaledb backup info command
+var backupInfoCmd = &cobra.Command{
+	Use:   "info [label]",
+	Short: "Shows the state of the backup repository or the details of a backup",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  backupInfo,
+}
+
+func init() {
+	backupCmd.AddCommand(backupInfoCmd)
+}
+
+// StanzaInfoResult is the result of the backup info command without a label
+type StanzaInfoResult struct {
+	Stanza          string `json:"stanza"`
+	Status          string `json:"status"`
+	PostgresVersion string `json:"postgresVersion"`
+	WALMin          string `json:"walMin"`
+	WALMax          string `json:"walMax"`
+	Backups         int    `json:"backups"`
+	// OldestRestorePoint is the earliest time a point in time restore can recover to
+	OldestRestorePoint *time.Time `json:"oldestRestorePoint,omitempty"`
+	LatestBackup       string     `json:"latestBackup,omitempty"`
+}
+
+func (s *StanzaInfoResult) PrintText(w io.Writer) error {
+	table := tablewriter.NewWriter(w)
+	table.Append([]string{"Stanza", s.Stanza})
+	table.Append([]string{"Status", s.Status})
+	table.Append([]string{"PostgreSQL Version", s.PostgresVersion})
+	table.Append([]string{"WAL Archive", s.WALMin + " / " + s.WALMax})
+	table.Append([]string{"Backups", strconv.Itoa(s.Backups)})
+	if s.OldestRestorePoint != nil {
+		table.Append([]string{"Oldest Restore Point", s.OldestRestorePoint.Format(time.RFC3339)})
+	}
+	table.Append([]string{"Latest Backup", s.LatestBackup})
+	table.Render()
+	return nil
+}
+
+// BackupInfoResult is the result of the backup info command for a backup
+type BackupInfoResult struct {
+	BackupDetails
+	Prior     string `json:"prior,omitempty"`
+	WALStart  string `json:"walStart"`
+	WALStop   string `json:"walStop"`
+	LSNStart  string `json:"lsnStart"`
+	LSNStop   string `json:"lsnStop"`
+	DeltaSize int64  `json:"deltaBytes"`
+}
+
+func (b *BackupInfoResult) PrintText(w io.Writer) error {
+	table := tablewriter.NewWriter(w)
+	table.Append([]string{"Label", b.Label})
+	table.Append([]string{"Type", b.Type})
+	table.Append([]string{"Prior", b.Prior})
+	table.Append([]string{"Start", b.Start.Format(time.RFC3339)})
+	table.Append([]string{"Stop", b.Stop.Format(time.RFC3339)})
+	table.Append([]string{"WAL Start / Stop", b.WALStart + " / " + b.WALStop})
+	table.Append([]string{"LSN Start / Stop", b.LSNStart + " / " + b.LSNStop})
+	table.Append([]string{"Database Size", common.FormatBytes(b.Size)})
+	table.Append([]string{"Backed Up Size", common.FormatBytes(b.DeltaSize)})
+	table.Append([]string{"Backup Size", common.FormatBytes(b.RepoSize)})
+	table.Append([]string{"Error", fmt.Sprint(b.Error)})
+	table.Render()
+	return nil
+}
+
+func backupInfo(cmd *cobra.Command, args []string) error {
+	k8sClient := k8s.NewClient()
+	pod, err := getMasterPod(k8sClient)
+	if err != nil {
+		return fmt.Errorf("could not get backup info: %w", err)
+	}
+
+	s, err := getStanzaInfo(k8sClient, pod)
+	if err != nil {
+		return fmt.Errorf("could not get backup info: %w", err)
+	}
+
+	if len(args) == 1 {
+		b, err := s.GetBackup(args[0])
+		if err != nil {
+			return fmt.Errorf("could not get backup info: %w", err)
+		}
+		return common.PrintResult(&BackupInfoResult{
+			BackupDetails: newBackupDetails(*b),
+			Prior:         b.Prior,
+			WALStart:      b.Archive.Start,
+			WALStop:       b.Archive.Stop,
+			LSNStart:      b.LSN.Start,
+			LSNStop:       b.LSN.Stop,
+			DeltaSize:     b.Info.Delta,
+		})
+	}
+
+	res := &StanzaInfoResult{
+		Stanza:  s.Name,
+		Status:  s.Status.Message,
+		Backups: len(s.Backups),
+	}
+	if len(s.DB) > 0 {
+		res.PostgresVersion = s.DB[len(s.DB)-1].Version
+	}
+	if len(s.Archive) > 0 {
+		res.WALMin = s.Archive[len(s.Archive)-1].Min
+		res.WALMax = s.Archive[len(s.Archive)-1].Max
+	}
+	for _, b := range s.Backups {
+		if b.Error {
+			continue
+		}
+		if res.OldestRestorePoint == nil {
+			t := b.StopTime()
+			res.OldestRestorePoint = &t
+		}
+		res.LatestBackup = b.Label
+	}
+	return common.PrintResult(res)
+}
@@ -0,0 +1,90 @@
+package backup
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"github.com/timescale/tobs/cli/cmd/common"
+	"github.com/timescale/tobs/cli/pkg/k8s"
+	"github.com/timescale/tobs/cli/pkg/pgbackrest"
+)
+
+// backupListCmd represents the timescaledb backup list command
+var backupListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the pgBackRest backups of TimescaleDB",
+	Args:  cobra.ExactArgs(0),
+	RunE:  backupList,
+}
+
+func init() {
+	backupCmd.AddCommand(backupListCmd)
+}
+
+// BackupDetails is a backup of the backup list command
+type BackupDetails struct {
+	Label    string    `json:"label"`
+	Type     string    `json:"type"`
+	Start    time.Time `json:"start"`
+	Stop     time.Time `json:"stop"`
+	Size     int64     `json:"sizeBytes"`
+	RepoSize int64     `json:"repoSizeBytes"`
+	Error    bool      `json:"error"`
+}
+
+// BackupListResult is the result of the backup list command
+type BackupListResult struct {
+	Backups []BackupDetails `json:"backups"`
+}
+
+func (b *BackupListResult) PrintText(w io.Writer) error {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Label", "Type", "Start", "Stop", "Database Size", "Backup Size", "Error"})
+	for _, d := range b.Backups {
+		table.Append([]string{
+			d.Label,
+			d.Type,
+			d.Start.Format(time.RFC3339),
+			d.Stop.Format(time.RFC3339),
+			common.FormatBytes(d.Size),
+			common.FormatBytes(d.RepoSize),
+			fmt.Sprint(d.Error),
+		})
+	}
+	table.Render()
+	return nil
+}
+
+func newBackupDetails(b pgbackrest.Backup) BackupDetails {
+	return BackupDetails{
+		Label:    b.Label,
+		Type:     b.Type,
+		Start:    b.StartTime(),
+		Stop:     b.StopTime(),
+		Size:     b.Info.Size,
+		RepoSize: b.Info.Repository.Size,
+		Error:    b.Error,
+	}
+}
+
+func backupList(cmd *cobra.Command, args []string) error {
+	k8sClient := k8s.NewClient()
+	pod, err := getMasterPod(k8sClient)
+	if err != nil {
+		return fmt.Errorf("could not list backups: %w", err)
+	}
+
+	s, err := getStanzaInfo(k8sClient, pod)
+	if err != nil {
+		return fmt.Errorf("could not list backups: %w", err)
+	}
+
+	res := &BackupListResult{}
+	for _, b := range s.Backups {
+		res.Backups = append(res.Backups, newBackupDetails(b))
+	}
+	return common.PrintResult(res)
+}
@@ -0,0 +1,134 @@
+package backup
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/timescale/tobs/cli/cmd/timescaledb"
+	"github.com/timescale/tobs/cli/pkg/k8s"
+	"github.com/timescale/tobs/cli/pkg/patroni"
+	"github.com/timescale/tobs/cli/pkg/utils"
+)
+
+// restoreCmd represents the timescaledb restore command
+var restoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Restores TimescaleDB to a point in time from the pgBackRest backups",
+	Long: `Restores TimescaleDB in place to a point in time from the pgBackRest backups.
+
+Patroni is paused while PostgreSQL on the master pod is stopped, restored from the latest
+backup which finished before the target time and recovered up to it. The replicas are then
+reinitialized from the restored master. All the data written after the target time is lost.`,
+	Example: `  tobs timescaledb restore --to 2021-10-20T11:30:00Z`,
+	Args:    cobra.ExactArgs(0),
+	RunE:    restore,
+}
+
+func init() {
+	timescaledb.Cmd.AddCommand(restoreCmd)
+	restoreCmd.Flags().String("to", "", "time to restore to, in RFC3339 or 'YYYY-MM-DD HH:MM:SS' UTC format")
+	restoreCmd.Flags().BoolP("confirm", "y", false, "Confirm the restore without prompting")
+	_ = restoreCmd.MarkFlagRequired("to")
+}
+
+func parseRestoreTarget(s string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02T15:04:05"} {
+		t, err := time.Parse(layout, s)
+		if err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q, use RFC3339 or 'YYYY-MM-DD HH:MM:SS' UTC format", s)
+}
+
+func restore(cmd *cobra.Command, args []string) error {
+	to, err := cmd.Flags().GetString("to")
+	if err != nil {
+		return fmt.Errorf("could not restore: %w", err)
+	}
+
+	confirm, err := cmd.Flags().GetBool("confirm")
+	if err != nil {
+		return fmt.Errorf("could not restore: %w", err)
+	}
+
+	target, err := parseRestoreTarget(to)
+	if err != nil {
+		return fmt.Errorf("could not restore: %w", err)
+	}
+	if target.After(time.Now()) {
+		return fmt.Errorf("could not restore: %s is in the future", target.Format(time.RFC3339))
+	}
+
+	k8sClient := k8s.NewClient()
+	pod, err := getMasterPod(k8sClient)
+	if err != nil {
+		return fmt.Errorf("could not restore: %w", err)
+	}
+
+	s, err := getStanzaInfo(k8sClient, pod)
+	if err != nil {
+		return fmt.Errorf("could not restore: %w", err)
+	}
+	b, err := s.LatestBackupBefore(target)
+	if err != nil {
+		return fmt.Errorf("could not restore: %w", err)
+	}
+
+	out, err := timescaledb.ExecOutput(k8sClient, pod, "patronictl list --format json")
+	if err != nil {
+		return fmt.Errorf("could not restore: %w", err)
+	}
+	members, err := patroni.ParseMembers(out)
+	if err != nil {
+		return fmt.Errorf("could not restore: %w", err)
+	}
+
+	fmt.Printf("TimescaleDB in pod %s will be restored to %s from backup %s.\n", pod, target.Format(time.RFC3339), b.Label)
+	fmt.Println("All the data written after that time will be lost and the replicas will be reinitialized.")
+	if !confirm {
+		utils.ConfirmAction()
+	}
+
+	// keep patroni from failing over or restarting postgres during the restore
+	err = timescaledb.ExecStep(k8sClient, pod, "Pausing Patroni", "patronictl pause")
+	if err != nil {
+		return fmt.Errorf("could not restore: %w", err)
+	}
+
+	steps := []struct {
+		description string
+		command     string
+	}{
+		{"Stopping PostgreSQL", `pg_ctl stop -D "$PGDATA" -m fast`},
+		{"Restoring backup " + b.Label, fmt.Sprintf("pgbackrest --stanza=%s --delta --set=%s --type=time '--target=%s' --target-action=promote --log-level-console=info restore",
+			stanza, b.Label, target.Format("2006-01-02 15:04:05+00"))},
+		{"Starting PostgreSQL", `pg_ctl start -D "$PGDATA" -w -t 3600 -l /tmp/tobs-restore.log`},
+	}
+	for _, step := range steps {
+		err = timescaledb.ExecStep(k8sClient, pod, step.description, step.command)
+		if err != nil {
+			return fmt.Errorf("could not restore, Patroni is left paused to investigate, resume it with patronictl resume: %w", err)
+		}
+	}
+
+	err = timescaledb.ExecStep(k8sClient, pod, "Resuming Patroni", "patronictl resume")
+	if err != nil {
+		return fmt.Errorf("could not restore: %w", err)
+	}
+
+	// the replicas are ahead of the restored timeline and can't follow it
+	for _, m := range members {
+		if m.IsLeader() {
+			continue
+		}
+		err = timescaledb.ExecStep(k8sClient, pod, "Reinitializing replica "+m.Name, fmt.Sprintf("patronictl reinit %s %s --force", m.Cluster, m.Name))
+		if err != nil {
+			return fmt.Errorf("restored the master but could not reinitialize replica %s: %w", m.Name, err)
+		}
+	}
+
+	fmt.Printf("Restored TimescaleDB to %s\n", target.Format(time.RFC3339))
+	return nil
+}
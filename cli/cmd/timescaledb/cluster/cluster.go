@@ -0,0 +1,78 @@
+package cluster
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	root "github.com/timescale/tobs/cli/cmd"
+	"github.com/timescale/tobs/cli/cmd/common"
+	"github.com/timescale/tobs/cli/cmd/timescaledb"
+	"github.com/timescale/tobs/cli/pkg/k8s"
+	"github.com/timescale/tobs/cli/pkg/patroni"
+)
+
+// clusterCmd represents the timescaledb cluster command
+var clusterCmd = &cobra.Command{
+	Use:   "cluster",
+	Short: "Subcommand for the Patroni cluster of TimescaleDB",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		err := root.RootCmd.PersistentPreRunE(cmd, args)
+		if err != nil {
+			return fmt.Errorf("could not read global flag: %w", err)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	timescaledb.Cmd.AddCommand(clusterCmd)
+}
+
+// getPod returns a running TimescaleDB pod to run patronictl in
+func getPod(k8sClient k8s.Client) (string, error) {
+	pods, err := k8sClient.KubeGetPods(root.Namespace, common.GetTimescaleDBLabels(root.HelmReleaseName))
+	if err != nil {
+		return "", err
+	}
+	for _, p := range pods {
+		if p.Status.Phase == "Running" && p.DeletionTimestamp == nil {
+			return p.Name, nil
+		}
+	}
+	return "", fmt.Errorf("no running TimescaleDB pod found")
+}
+
+func listMembers(k8sClient k8s.Client, pod string) ([]patroni.Member, error) {
+	out, err := timescaledb.ExecOutput(k8sClient, pod, "patronictl list --format json")
+	if err != nil {
+		return nil, err
+	}
+	return patroni.ParseMembers(out)
+}
+
+// waitForNewLeader waits until a member other than the old leader, or the candidate
+// when it's set, leads the cluster and is running. The pod patronictl runs in can be
+// restarted by the switchover so any running TimescaleDB pod is used for each check.
+func waitForNewLeader(k8sClient k8s.Client, oldLeader, candidate string, timeout time.Duration) (*patroni.Member, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		pod, err := getPod(k8sClient)
+		if err == nil {
+			var members []patroni.Member
+			members, err = listMembers(k8sClient, pod)
+			if err == nil {
+				leader, err := patroni.GetLeader(members)
+				if err == nil && leader.Name != oldLeader && (candidate == "" || leader.Name == candidate) && leader.State == "running" {
+					return leader, nil
+				}
+			}
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("no new leader after %v", timeout)
+		}
+		time.Sleep(2 * time.Second)
+	}
+}
@@ -0,0 +1,91 @@
+package cluster
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/timescale/tobs/cli/cmd/timescaledb"
+	"github.com/timescale/tobs/cli/pkg/k8s"
+	"github.com/timescale/tobs/cli/pkg/utils"
+)
+
+// clusterFailoverCmd represents the timescaledb cluster failover command
+var clusterFailoverCmd = &cobra.Command{
+	Use:   "failover",
+	Short: "Fails the Patroni cluster over to a replica",
+	Long: `Fails the Patroni cluster over to the given candidate, even if the cluster has no
+healthy leader, and waits until the new leader is running. Prefer switchover on a healthy cluster.`,
+	Example: `  tobs timescaledb cluster failover --candidate tobs-timescaledb-1`,
+	Args:    cobra.ExactArgs(0),
+	RunE:    clusterFailover,
+}
+
+func init() {
+	clusterCmd.AddCommand(clusterFailoverCmd)
+	clusterFailoverCmd.Flags().String("candidate", "", "member to become the new leader")
+	clusterFailoverCmd.Flags().Duration("timeout", 5*time.Minute, "time to wait for the new leader to be running")
+	clusterFailoverCmd.Flags().BoolP("confirm", "y", false, "Confirm the failover without prompting")
+	_ = clusterFailoverCmd.MarkFlagRequired("candidate")
+}
+
+func clusterFailover(cmd *cobra.Command, args []string) error {
+	candidate, err := cmd.Flags().GetString("candidate")
+	if err != nil {
+		return fmt.Errorf("could not fail over: %w", err)
+	}
+
+	timeout, err := cmd.Flags().GetDuration("timeout")
+	if err != nil {
+		return fmt.Errorf("could not fail over: %w", err)
+	}
+
+	confirm, err := cmd.Flags().GetBool("confirm")
+	if err != nil {
+		return fmt.Errorf("could not fail over: %w", err)
+	}
+
+	k8sClient := k8s.NewClient()
+	pod, err := getPod(k8sClient)
+	if err != nil {
+		return fmt.Errorf("could not fail over: %w", err)
+	}
+
+	members, err := listMembers(k8sClient, pod)
+	if err != nil {
+		return fmt.Errorf("could not fail over: %w", err)
+	}
+	if len(members) == 0 {
+		return fmt.Errorf("could not fail over: the cluster has no members")
+	}
+	err = validateCandidate(members, candidate)
+	if err != nil {
+		return fmt.Errorf("could not fail over: %w", err)
+	}
+
+	// the cluster may have no leader at all, which is when failover is used
+	oldLeader := ""
+	for _, m := range members {
+		if m.IsLeader() {
+			oldLeader = m.Name
+		}
+	}
+
+	cluster := members[0].Cluster
+	fmt.Printf("Cluster %s will fail over to %s.\n", cluster, candidate)
+	if !confirm {
+		utils.ConfirmAction()
+	}
+
+	err = timescaledb.ExecStep(k8sClient, pod, "Failing over", fmt.Sprintf("patronictl failover %s --candidate %s --force", cluster, candidate))
+	if err != nil {
+		return fmt.Errorf("could not fail over: %w", err)
+	}
+
+	newLeader, err := waitForNewLeader(k8sClient, oldLeader, candidate, timeout)
+	if err != nil {
+		return fmt.Errorf("could not fail over: %w", err)
+	}
+	fmt.Printf("Failed over to leader %s on timeline %d\n", newLeader.Name, newLeader.Timeline)
+	return nil
+}
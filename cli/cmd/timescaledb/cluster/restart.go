@@ -0,0 +1,93 @@
+package cluster
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/timescale/tobs/cli/cmd/timescaledb"
+	"github.com/timescale/tobs/cli/pkg/k8s"
+	"github.com/timescale/tobs/cli/pkg/utils"
+)
+
+// clusterRestartCmd represents the timescaledb cluster restart command
+var clusterRestartCmd = &cobra.Command{
+	Use:   "restart [member]",
+	Short: "Restarts PostgreSQL on the members of the Patroni cluster",
+	Long: `Restarts PostgreSQL through Patroni on the given member, or on all the members.
+With --pending only the members with a pending restart are restarted.`,
+	Example: `  tobs timescaledb cluster restart --pending`,
+	Args:    cobra.MaximumNArgs(1),
+	RunE:    clusterRestart,
+}
+
+func init() {
+	clusterCmd.AddCommand(clusterRestartCmd)
+	clusterRestartCmd.Flags().Bool("pending", false, "restart only the members with a pending restart")
+	clusterRestartCmd.Flags().BoolP("confirm", "y", false, "Confirm the restart without prompting")
+}
+
+func clusterRestart(cmd *cobra.Command, args []string) error {
+	pending, err := cmd.Flags().GetBool("pending")
+	if err != nil {
+		return fmt.Errorf("could not restart: %w", err)
+	}
+
+	confirm, err := cmd.Flags().GetBool("confirm")
+	if err != nil {
+		return fmt.Errorf("could not restart: %w", err)
+	}
+
+	k8sClient := k8s.NewClient()
+	pod, err := getPod(k8sClient)
+	if err != nil {
+		return fmt.Errorf("could not restart: %w", err)
+	}
+
+	members, err := listMembers(k8sClient, pod)
+	if err != nil {
+		return fmt.Errorf("could not restart: %w", err)
+	}
+	if len(members) == 0 {
+		return fmt.Errorf("could not restart: the cluster has no members")
+	}
+	cluster := members[0].Cluster
+
+	member := ""
+	if len(args) == 1 {
+		member = args[0]
+		found := false
+		for _, m := range members {
+			found = found || m.Name == member
+		}
+		if !found {
+			return fmt.Errorf("could not restart: %s is not a member of the cluster", member)
+		}
+	}
+
+	target := "all the members"
+	if member != "" {
+		target = "member " + member
+	}
+	if pending {
+		target += " with a pending restart"
+	}
+	fmt.Printf("PostgreSQL will be restarted on %s of cluster %s.\n", target, cluster)
+	if !confirm {
+		utils.ConfirmAction()
+	}
+
+	command := "patronictl restart " + cluster
+	if member != "" {
+		command += " " + member
+	}
+	if pending {
+		command += " --pending"
+	}
+	err = timescaledb.ExecStep(k8sClient, pod, "Restarting", command+" --force")
+	if err != nil {
+		return fmt.Errorf("could not restart: %w", err)
+	}
+
+	fmt.Printf("Restarted %s\n", target)
+	return nil
+}
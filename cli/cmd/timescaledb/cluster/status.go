@@ -0,0 +1,77 @@
+package cluster
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"github.com/timescale/tobs/cli/cmd/common"
+	"github.com/timescale/tobs/cli/pkg/k8s"
+	"github.com/timescale/tobs/cli/pkg/patroni"
+)
+
+// clusterStatusCmd represents the timescaledb cluster status command
+var clusterStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Shows the members of the Patroni cluster with their role, timeline and replication lag",
+	Args:  cobra.ExactArgs(0),
+	RunE:  clusterStatus,
+}
+
+func init() {
+	clusterCmd.AddCommand(clusterStatusCmd)
+}
+
+// ClusterStatusResult is the result of the cluster status command
+type ClusterStatusResult struct {
+	Cluster  string           `json:"cluster"`
+	Leader   string           `json:"leader"`
+	Timeline int              `json:"timeline"`
+	Members  []patroni.Member `json:"members"`
+}
+
+func (c *ClusterStatusResult) PrintText(w io.Writer) error {
+	fmt.Fprintf(w, "Cluster: %s, leader: %s, timeline: %d\n", c.Cluster, c.Leader, c.Timeline)
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Member", "Host", "Role", "State", "Timeline", "Lag in MB", "Pending Restart"})
+	for _, m := range c.Members {
+		lag := ""
+		if m.LagMB != nil {
+			lag = strconv.FormatFloat(*m.LagMB, 'f', -1, 64)
+		} else if !m.IsLeader() {
+			lag = "unknown"
+		}
+		pendingRestart := ""
+		if m.PendingRestart {
+			pendingRestart = "*"
+		}
+		table.Append([]string{m.Name, m.Host, m.Role, m.State, strconv.Itoa(m.Timeline), lag, pendingRestart})
+	}
+	table.Render()
+	return nil
+}
+
+func clusterStatus(cmd *cobra.Command, args []string) error {
+	k8sClient := k8s.NewClient()
+	pod, err := getPod(k8sClient)
+	if err != nil {
+		return fmt.Errorf("could not get cluster status: %w", err)
+	}
+
+	members, err := listMembers(k8sClient, pod)
+	if err != nil {
+		return fmt.Errorf("could not get cluster status: %w", err)
+	}
+
+	res := &ClusterStatusResult{Members: members}
+	if len(members) > 0 {
+		res.Cluster = members[0].Cluster
+	}
+	if leader, err := patroni.GetLeader(members); err == nil {
+		res.Leader = leader.Name
+		res.Timeline = leader.Timeline
+	}
+	return common.PrintResult(res)
+}
@@ -0,0 +1,108 @@
+package cluster
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/timescale/tobs/cli/cmd/timescaledb"
+	"github.com/timescale/tobs/cli/pkg/k8s"
+	"github.com/timescale/tobs/cli/pkg/patroni"
+	"github.com/timescale/tobs/cli/pkg/utils"
+)
+
+// clusterSwitchoverCmd represents the timescaledb cluster switchover command
+var clusterSwitchoverCmd = &cobra.Command{
+	Use:   "switchover",
+	Short: "Switches the leader of the Patroni cluster over to a healthy replica",
+	Long: `Switches the leader of the Patroni cluster over to the given candidate, or to
+the healthiest replica when no candidate is set, and waits until the new leader is running.`,
+	Example: `  tobs timescaledb cluster switchover --candidate tobs-timescaledb-1`,
+	Args:    cobra.ExactArgs(0),
+	RunE:    clusterSwitchover,
+}
+
+func init() {
+	clusterCmd.AddCommand(clusterSwitchoverCmd)
+	clusterSwitchoverCmd.Flags().String("candidate", "", "member to become the new leader")
+	clusterSwitchoverCmd.Flags().Duration("timeout", 5*time.Minute, "time to wait for the new leader to be running")
+	clusterSwitchoverCmd.Flags().BoolP("confirm", "y", false, "Confirm the switchover without prompting")
+}
+
+func clusterSwitchover(cmd *cobra.Command, args []string) error {
+	candidate, err := cmd.Flags().GetString("candidate")
+	if err != nil {
+		return fmt.Errorf("could not switch over: %w", err)
+	}
+
+	timeout, err := cmd.Flags().GetDuration("timeout")
+	if err != nil {
+		return fmt.Errorf("could not switch over: %w", err)
+	}
+
+	confirm, err := cmd.Flags().GetBool("confirm")
+	if err != nil {
+		return fmt.Errorf("could not switch over: %w", err)
+	}
+
+	k8sClient := k8s.NewClient()
+	pod, err := getPod(k8sClient)
+	if err != nil {
+		return fmt.Errorf("could not switch over: %w", err)
+	}
+
+	members, err := listMembers(k8sClient, pod)
+	if err != nil {
+		return fmt.Errorf("could not switch over: %w", err)
+	}
+	leader, err := patroni.GetLeader(members)
+	if err != nil {
+		return fmt.Errorf("could not switch over: %w", err)
+	}
+	err = validateCandidate(members, candidate)
+	if err != nil {
+		return fmt.Errorf("could not switch over: %w", err)
+	}
+
+	if candidate != "" {
+		fmt.Printf("The leader of cluster %s will be switched over from %s to %s.\n", leader.Cluster, leader.Name, candidate)
+	} else {
+		fmt.Printf("The leader of cluster %s will be switched over from %s.\n", leader.Cluster, leader.Name)
+	}
+	if !confirm {
+		utils.ConfirmAction()
+	}
+
+	command := fmt.Sprintf("patronictl switchover %s --master %s --force", leader.Cluster, leader.Name)
+	if candidate != "" {
+		command += " --candidate " + candidate
+	}
+	err = timescaledb.ExecStep(k8sClient, pod, "Switching over", command)
+	if err != nil {
+		return fmt.Errorf("could not switch over: %w", err)
+	}
+
+	newLeader, err := waitForNewLeader(k8sClient, leader.Name, candidate, timeout)
+	if err != nil {
+		return fmt.Errorf("could not switch over: %w", err)
+	}
+	fmt.Printf("Switched over to leader %s on timeline %d\n", newLeader.Name, newLeader.Timeline)
+	return nil
+}
+
+// validateCandidate checks the candidate, if set, is a replica of the cluster
+func validateCandidate(members []patroni.Member, candidate string) error {
+	if candidate == "" {
+		return nil
+	}
+	for _, m := range members {
+		if m.Name != candidate {
+			continue
+		}
+		if m.IsLeader() {
+			return fmt.Errorf("candidate %s is already the leader", candidate)
+		}
+		return nil
+	}
+	return fmt.Errorf("candidate %s is not a member of the cluster", candidate)
+}
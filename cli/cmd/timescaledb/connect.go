@@ -3,7 +3,11 @@ package timescaledb
 import (
 	"fmt"
 	"log"
+	"net/url"
 	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -14,20 +18,42 @@ import (
 	"github.com/timescale/tobs/cli/pkg/pgconn"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+)
+
+const (
+	// ConnectModePod runs psql in a pod inside the cluster
+	ConnectModePod = "pod"
+	// ConnectModeLocal runs the local psql through a port-forward
+	ConnectModeLocal = "local"
+	// ConnectModeREPL runs the built-in SQL REPL through a port-forward
+	ConnectModeREPL = "repl"
 )
 
 // timescaledbConnectCmd represents the timescaledb connect command
 var timescaledbConnectCmd = &cobra.Command{
 	Use:   "connect <user>",
 	Short: "Connects to the TimescaleDB database with provided user",
-	Args:  cobra.ExactArgs(1),
-	RunE:  timescaledbConnect,
+	Long: `Connects to the TimescaleDB database with provided user.
+
+The --mode flag selects how the session is run:
+  pod    runs psql in a temporary pod inside the cluster (default)
+  local  port-forwards to the master pod and runs the local psql
+  repl   port-forwards to the master pod and runs a built-in SQL REPL, which needs no psql at all`,
+	Args: cobra.ExactArgs(1),
+	RunE: timescaledbConnect,
 }
 
 func init() {
 	Cmd.AddCommand(timescaledbConnectCmd)
 	timescaledbConnectCmd.Flags().BoolP("master", "m", false, "directly execute session on master node")
 	timescaledbConnectCmd.Flags().StringP("dbname", "d", "", "database name to connect to, defaults to dbname from the helm release")
+	AddConnectModeFlag(timescaledbConnectCmd)
+}
+
+// AddConnectModeFlag adds the --mode flag selecting how to run the database session
+func AddConnectModeFlag(cmd *cobra.Command) {
+	cmd.Flags().String("mode", ConnectModePod, "how to run the session, one of: pod|local|repl")
 }
 
 func timescaledbConnect(cmd *cobra.Command, args []string) error {
@@ -41,6 +67,11 @@ func timescaledbConnect(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("could not connect to TimescaleDB: %w", err)
 	}
 
+	mode, err := cmd.Flags().GetString("mode")
+	if err != nil {
+		return fmt.Errorf("could not connect to TimescaleDB: %w", err)
+	}
+
 	if dbname == "" {
 		// if dbname is empty get the default db name from helm release
 		dbname, err = getDBNameFromValues()
@@ -60,7 +91,26 @@ func timescaledbConnect(cmd *cobra.Command, args []string) error {
 		User:        args[0],
 	}
 	k8sClient := k8s.NewClient()
-	return PsqlConnect(k8sClient, dbDetails, master)
+	return Connect(k8sClient, dbDetails, master, mode)
+}
+
+// Connect runs an interactive database session in the given connect mode,
+// master executes the session on the master pod regardless of the mode
+func Connect(k8sClient k8s.Client, dbDetails *pgconn.DBDetails, master bool, mode string) error {
+	if master {
+		return PsqlConnect(k8sClient, dbDetails, master)
+	}
+
+	switch mode {
+	case ConnectModePod:
+		return PsqlConnect(k8sClient, dbDetails, master)
+	case ConnectModeLocal:
+		return LocalPsqlConnect(k8sClient, dbDetails)
+	case ConnectModeREPL:
+		return REPLConnect(k8sClient, dbDetails)
+	default:
+		return fmt.Errorf("could not connect to TimescaleDB: unknown mode %q, must be one of: pod|local|repl", mode)
+	}
 }
 
 func getDBNameFromValues() (string, error) {
@@ -88,14 +138,26 @@ func PsqlConnect(k8sClient k8s.Client, dbDetails *pgconn.DBDetails, master bool)
 	if err != nil {
 		return err
 	}
+	password := dbDetails.Password
 	if uri == "" {
 		host = root.HelmReleaseName + "." + root.Namespace + ".svc"
 		psqlCMD = "psql -U " + dbDetails.User + " -h " + host + " " + dbDetails.DBName
 	} else {
+		// the password goes to the PGPASSWORD env of the pod instead of the pod spec args
+		var uriPassword string
+		uri, uriPassword, err = splitURIPassword(uri)
+		if err != nil {
+			return fmt.Errorf("could not connect to TimescaleDB: %w", err)
+		}
+		if uriPassword != "" {
+			password = uriPassword
+		}
 		psqlCMD = "psql " + uri
 	}
 
-	pod := formPsqlPodObject(dbDetails.DBName, root.Namespace, dbDetails.User, dbDetails.Password, host, uri)
+	// a unique name lets several users connect at the same time
+	podName := "psql-" + utilrand.String(5)
+	pod := formPsqlPodObject(podName, dbDetails.DBName, root.Namespace, dbDetails.User, password, host, uri)
 
 	err = k8sClient.KubeCreatePod(pod)
 	if err != nil {
@@ -105,18 +167,18 @@ func PsqlConnect(k8sClient k8s.Client, dbDetails *pgconn.DBDetails, master bool)
 	time.Sleep(time.Second)
 
 	defer func() {
-		err = k8sClient.KubeDeletePod(root.Namespace, "psql")
+		err = k8sClient.KubeDeletePod(root.Namespace, podName)
 		if err != nil {
 			log.Fatalf("failed to delete psql pod %v", err)
 		}
 	}()
 
-	err = k8sClient.KubeWaitOnPod(root.Namespace, "psql")
+	err = k8sClient.KubeWaitOnPod(root.Namespace, podName)
 	if err != nil {
 		return fmt.Errorf("failed to wait for psql pod: %w", err)
 	}
 
-	err = k8sClient.KubeExecCmd(root.Namespace, "psql", "", psqlCMD, os.Stdin, true)
+	err = k8sClient.KubeExecCmd(root.Namespace, podName, "", psqlCMD, os.Stdin, true)
 	if err != nil {
 		return fmt.Errorf("could not connect to TimescaleDB with psql pod: %w", err)
 	}
@@ -124,16 +186,93 @@ func PsqlConnect(k8sClient k8s.Client, dbDetails *pgconn.DBDetails, master bool)
 	return nil
 }
 
-func formPsqlPodObject(name, namespace, user, pass, host, uri string) *corev1.Pod {
+// LocalPsqlConnect runs the local psql against the database, port-forwarding
+// to the master pod unless the release uses an external database
+func LocalPsqlConnect(k8sClient k8s.Client, dbDetails *pgconn.DBDetails) error {
+	psql, err := exec.LookPath("psql")
+	if err != nil {
+		return fmt.Errorf("could not connect to TimescaleDB: psql not found, use --mode repl to connect without it: %w", err)
+	}
+
+	uri, err := common.GetTimescaleDBURI(k8sClient, root.Namespace, root.HelmReleaseName)
+	if err != nil {
+		return err
+	}
+
+	c := exec.Command(psql)
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	if uri != "" {
+		// the password is passed through the environment to keep it out of the process list
+		uri, password, err := splitURIPassword(uri)
+		if err != nil {
+			return fmt.Errorf("could not connect to TimescaleDB: %w", err)
+		}
+		c.Args = append(c.Args, uri)
+		c.Env = os.Environ()
+		if password != "" {
+			c.Env = append(c.Env, "PGPASSWORD="+password)
+		}
+	} else {
+		pf, err := PortForwardTimescaleDB(0)
+		if err != nil {
+			return fmt.Errorf("could not connect to TimescaleDB: %w", err)
+		}
+		defer pf.Stop()
+
+		// the password is passed through the environment to keep it out of the process list
+		c.Env = append(os.Environ(),
+			"PGHOST=localhost",
+			"PGPORT="+strconv.Itoa(pf.LocalPort()),
+			"PGUSER="+dbDetails.User,
+			"PGDATABASE="+dbDetails.DBName,
+		)
+		if dbDetails.Password != "" {
+			c.Env = append(c.Env, "PGPASSWORD="+dbDetails.Password)
+		}
+	}
+
+	// Ctrl-C cancels the query in psql, it must not stop tobs
+	// and the port-forward the psql session runs through
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	err = c.Run()
+	signal.Stop(interrupts)
+	if err != nil {
+		return fmt.Errorf("could not connect to TimescaleDB with psql: %w", err)
+	}
+
+	return nil
+}
+
+// splitURIPassword returns the connection URI without its password, and the password
+func splitURIPassword(uri string) (string, string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", "", fmt.Errorf("could not parse the database URI: %w", err)
+	}
+	if u.User == nil {
+		return uri, "", nil
+	}
+	password, ok := u.User.Password()
+	if !ok {
+		return uri, "", nil
+	}
+	u.User = url.User(u.User.Username())
+	return u.String(), password, nil
+}
+
+func formPsqlPodObject(podName, dbName, namespace, user, pass, host, uri string) *corev1.Pod {
 	var args []string
 	if uri == "" {
-		args = []string{"-U", user, "-h", host, name}
+		args = []string{"-U", user, "-h", host, dbName}
 	} else {
 		args = []string{uri}
 	}
 	return &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "psql",
+			Name:      podName,
 			Namespace: namespace,
 			Labels: map[string]string{
 				"app": "psql",
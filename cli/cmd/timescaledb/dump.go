@@ -0,0 +1,263 @@
+package timescaledb
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	root "github.com/timescale/tobs/cli/cmd"
+	"github.com/timescale/tobs/cli/cmd/common"
+	"github.com/timescale/tobs/cli/pkg/k8s"
+	"github.com/timescale/tobs/cli/pkg/pgconn"
+)
+
+// timescaledbDumpCmd represents the timescaledb dump command
+var timescaledbDumpCmd = &cobra.Command{
+	Use:   "dump",
+	Short: "Dumps the TimescaleDB database to a local file",
+	Long: `Dumps the TimescaleDB database to a local file in the pg_dump custom format, which
+tobs timescaledb load restores. pg_dump runs in the master pod and streams the dump back.
+
+With --promscale-only the schemas which don't belong to Promscale or TimescaleDB are left out.
+With --metric the schema is dumped whole but only the data of the given metrics is included.`,
+	Example: `  tobs timescaledb dump -f promscale.dump --promscale-only
+  tobs timescaledb dump -f cpu.dump --metric node_cpu_seconds_total,node_load1`,
+	Args: cobra.ExactArgs(0),
+	RunE: timescaledbDump,
+}
+
+func init() {
+	Cmd.AddCommand(timescaledbDumpCmd)
+	timescaledbDumpCmd.Flags().StringP("file", "f", "", "local file to write the dump to")
+	timescaledbDumpCmd.Flags().StringP("dbname", "d", "", "database name to dump, defaults to dbname from the helm release")
+	timescaledbDumpCmd.Flags().Bool("promscale-only", false, "dump only the Promscale and TimescaleDB schemas")
+	timescaledbDumpCmd.Flags().StringSlice("metric", nil, "dump only the data of these metrics")
+	_ = timescaledbDumpCmd.MarkFlagRequired("file")
+}
+
+// schemaPrefixes are the prefixes of the schemas created by Promscale and TimescaleDB
+var schemaPrefixes = []string{"_prom", "prom_", "_ps_", "ps_", "_timescaledb", "timescaledb_"}
+
+func isPromscaleSchema(schema string) bool {
+	for _, p := range schemaPrefixes {
+		if strings.HasPrefix(schema, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// shellQuote quotes s as a single word for /bin/sh
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// pgCommand returns the shell command running the client tool with the arguments,
+// connecting as the user and reading the password from the first line of stdin
+// so it doesn't show in the process list of the pod
+func pgCommand(d *pgconn.DBDetails, tool string, args ...string) string {
+	words := []string{tool, "-h", "localhost", "-U", shellQuote(d.User), "-d", shellQuote(d.DBName)}
+	for _, a := range args {
+		words = append(words, shellQuote(a))
+	}
+	return strings.Join(words, " ")
+}
+
+// passwordPrelude reads the password written first to stdin into PGPASSWORD
+const passwordPrelude = "IFS= read -r PGPASSWORD && export PGPASSWORD && "
+
+// getDumpPod returns the master pod to run pg_dump and pg_restore in
+func getDumpPod(k8sClient k8s.Client) (string, error) {
+	uri, err := common.GetTimescaleDBURI(k8sClient, root.Namespace, root.HelmReleaseName)
+	if err != nil {
+		return "", err
+	}
+	if uri != "" {
+		return "", fmt.Errorf("release %s uses an external database, run pg_dump and pg_restore against it directly", root.HelmReleaseName)
+	}
+	return k8sClient.KubeGetPodName(root.Namespace, map[string]string{"release": root.HelmReleaseName, "role": "master"})
+}
+
+func timescaledbDump(cmd *cobra.Command, args []string) error {
+	file, err := cmd.Flags().GetString("file")
+	if err != nil {
+		return fmt.Errorf("could not dump: %w", err)
+	}
+
+	dbname, err := cmd.Flags().GetString("dbname")
+	if err != nil {
+		return fmt.Errorf("could not dump: %w", err)
+	}
+
+	promscaleOnly, err := cmd.Flags().GetBool("promscale-only")
+	if err != nil {
+		return fmt.Errorf("could not dump: %w", err)
+	}
+
+	metrics, err := cmd.Flags().GetStringSlice("metric")
+	if err != nil {
+		return fmt.Errorf("could not dump: %w", err)
+	}
+
+	d, err := common.GetSuperuserDBDetails(root.Namespace, root.HelmReleaseName)
+	if err != nil {
+		return fmt.Errorf("could not dump: %w", err)
+	}
+	if dbname != "" {
+		d.DBName = dbname
+	}
+
+	k8sClient := k8s.NewClient()
+	pod, err := getDumpPod(k8sClient)
+	if err != nil {
+		return fmt.Errorf("could not dump: %w", err)
+	}
+
+	dumpArgs := []string{"--format=custom"}
+	if promscaleOnly || len(metrics) > 0 {
+		filterArgs, err := dumpFilterArgs(d, promscaleOnly, metrics)
+		if err != nil {
+			return fmt.Errorf("could not dump: %w", err)
+		}
+		dumpArgs = append(dumpArgs, filterArgs...)
+	}
+
+	f, err := os.Create(file)
+	if err != nil {
+		return fmt.Errorf("could not dump: %w", err)
+	}
+
+	fmt.Printf("Dumping database %s from pod %s to %s...\n", d.DBName, pod, file)
+	stdin := strings.NewReader(d.Password + "\n")
+	err = k8sClient.KubeExecCmdWithStreams(root.Namespace, pod, Container, passwordPrelude+"exec "+pgCommand(d, "pg_dump", dumpArgs...), stdin, f, os.Stderr)
+	if err1 := f.Close(); err == nil {
+		err = err1
+	}
+	if err != nil {
+		// don't leave a truncated dump behind
+		_ = os.Remove(file)
+		return fmt.Errorf("could not dump: pg_dump failed: %w", err)
+	}
+
+	fmt.Printf("Dumped database %s to %s\n", d.DBName, file)
+	return nil
+}
+
+// dumpFilterArgs returns the pg_dump arguments leaving out the schemas which don't belong
+// to Promscale and the data of the metrics which aren't selected
+func dumpFilterArgs(d *pgconn.DBDetails, promscaleOnly bool, metrics []string) ([]string, error) {
+	pool, err := d.OpenConnectionToDB()
+	if err != nil {
+		return nil, err
+	}
+	defer pool.Close()
+
+	ctx := context.Background()
+	var args []string
+
+	if promscaleOnly {
+		// the chunks of hypertables in other schemas are in the TimescaleDB
+		// schemas too, so they can't be left out consistently
+		rows, err := pool.Query(ctx, "SELECT schema_name::TEXT, table_name::TEXT FROM _timescaledb_catalog.hypertable")
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var schema, table string
+			err = rows.Scan(&schema, &table)
+			if err != nil {
+				rows.Close()
+				return nil, err
+			}
+			if !isPromscaleSchema(schema) {
+				rows.Close()
+				return nil, fmt.Errorf("hypertable %s.%s is outside the Promscale schemas, --promscale-only can't leave it out", schema, table)
+			}
+		}
+		rows.Close()
+		if rows.Err() != nil {
+			return nil, rows.Err()
+		}
+
+		var schemas []string
+		err = pool.QueryRow(ctx, "SELECT array_agg(nspname::TEXT) FROM pg_namespace WHERE nspname NOT LIKE 'pg\\_%' AND nspname <> 'information_schema'").Scan(&schemas)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range schemas {
+			if !isPromscaleSchema(s) {
+				args = append(args, "--exclude-schema="+pgDumpPatternQuote(s))
+			}
+		}
+	}
+
+	if len(metrics) > 0 {
+		excluded, err := excludedMetricData(ctx, pool, metrics)
+		if err != nil {
+			return nil, err
+		}
+		for _, pattern := range excluded {
+			args = append(args, "--exclude-table-data="+pattern)
+		}
+	}
+
+	return args, nil
+}
+
+// excludedMetricData returns the pg_dump patterns of the data tables, series tables and
+// chunks of all the metrics except the given ones, which must exist
+func excludedMetricData(ctx context.Context, q querier, metrics []string) ([]string, error) {
+	rows, err := q.Query(ctx, `SELECT m.metric_name, m.table_name, h.id, h.compressed_hypertable_id
+		FROM _prom_catalog.metric m
+		LEFT JOIN _timescaledb_catalog.hypertable h ON h.schema_name = 'prom_data' AND h.table_name = m.table_name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	selected := make(map[string]bool, len(metrics))
+	for _, m := range metrics {
+		selected[m] = false
+	}
+
+	var patterns []string
+	for rows.Next() {
+		var metric, table string
+		var hypertableID, compressedID *int32
+		err = rows.Scan(&metric, &table, &hypertableID, &compressedID)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := selected[metric]; ok {
+			selected[metric] = true
+			continue
+		}
+
+		quoted := pgDumpPatternQuote(table)
+		patterns = append(patterns, "prom_data."+quoted, "prom_data_series."+quoted)
+		if hypertableID != nil {
+			patterns = append(patterns, fmt.Sprintf("_timescaledb_internal._hyper_%d_*_chunk", *hypertableID))
+		}
+		if compressedID != nil {
+			patterns = append(patterns, fmt.Sprintf("_timescaledb_internal.compress_hyper_%d_*_chunk", *compressedID))
+		}
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+
+	for _, m := range metrics {
+		if !selected[m] {
+			return nil, fmt.Errorf("metric %s not found", m)
+		}
+	}
+	return patterns, nil
+}
+
+// pgDumpPatternQuote quotes the name in a pg_dump pattern so it
+// matches literally, without case folding or wildcards
+func pgDumpPatternQuote(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
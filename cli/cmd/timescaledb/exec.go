@@ -0,0 +1,36 @@
+package timescaledb
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	root "github.com/timescale/tobs/cli/cmd"
+	"github.com/timescale/tobs/cli/pkg/k8s"
+)
+
+// Container is the container of the TimescaleDB pods running
+// PostgreSQL, with patronictl, pgbackrest and the client tools
+const Container = "timescaledb"
+
+// ExecOutput runs the command in the TimescaleDB container of the pod and returns its output
+func ExecOutput(k8sClient k8s.Client, pod, command string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	err := k8sClient.KubeExecCmdWithStreams(root.Namespace, pod, Container, command, nil, &stdout, &stderr)
+	if err != nil {
+		return nil, fmt.Errorf("%s failed: %w: %s", command, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// ExecStep prints the description and runs the command in the
+// TimescaleDB container of the pod, streaming its output
+func ExecStep(k8sClient k8s.Client, pod, description, command string) error {
+	fmt.Println(description + "...")
+	err := k8sClient.KubeExecCmdWithStreams(root.Namespace, pod, Container, command, nil, os.Stdout, os.Stderr)
+	if err != nil {
+		return fmt.Errorf("%s failed: %w", command, err)
+	}
+	return nil
+}
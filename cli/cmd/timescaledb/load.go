@@ -0,0 +1,110 @@
+package timescaledb
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	root "github.com/timescale/tobs/cli/cmd"
+	"github.com/timescale/tobs/cli/cmd/common"
+	"github.com/timescale/tobs/cli/pkg/k8s"
+	"github.com/timescale/tobs/cli/pkg/utils"
+)
+
+// timescaledbLoadCmd represents the timescaledb load command
+var timescaledbLoadCmd = &cobra.Command{
+	Use:   "load",
+	Short: "Loads a dump made with tobs timescaledb dump into the TimescaleDB database",
+	Long: `Loads a dump made with tobs timescaledb dump into the TimescaleDB database. pg_restore
+runs in the master pod reading the local file, between timescaledb_pre_restore() and
+timescaledb_post_restore() as TimescaleDB requires.
+
+Scale Promscale down while loading, as the TimescaleDB background jobs are stopped and
+writes during the restore end up inconsistent. With --clean the objects in the dump
+are dropped before being recreated.`,
+	Example: `  tobs timescaledb load -f promscale.dump --clean`,
+	Args:    cobra.ExactArgs(0),
+	RunE:    timescaledbLoad,
+}
+
+func init() {
+	Cmd.AddCommand(timescaledbLoadCmd)
+	timescaledbLoadCmd.Flags().StringP("file", "f", "", "local dump file to load")
+	timescaledbLoadCmd.Flags().StringP("dbname", "d", "", "database name to load into, defaults to dbname from the helm release")
+	timescaledbLoadCmd.Flags().Bool("clean", false, "drop the objects in the dump before recreating them")
+	timescaledbLoadCmd.Flags().BoolP("confirm", "y", false, "Confirm the load without prompting")
+	_ = timescaledbLoadCmd.MarkFlagRequired("file")
+}
+
+func timescaledbLoad(cmd *cobra.Command, args []string) error {
+	file, err := cmd.Flags().GetString("file")
+	if err != nil {
+		return fmt.Errorf("could not load: %w", err)
+	}
+
+	dbname, err := cmd.Flags().GetString("dbname")
+	if err != nil {
+		return fmt.Errorf("could not load: %w", err)
+	}
+
+	clean, err := cmd.Flags().GetBool("clean")
+	if err != nil {
+		return fmt.Errorf("could not load: %w", err)
+	}
+
+	confirm, err := cmd.Flags().GetBool("confirm")
+	if err != nil {
+		return fmt.Errorf("could not load: %w", err)
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return fmt.Errorf("could not load: %w", err)
+	}
+	defer f.Close()
+
+	d, err := common.GetSuperuserDBDetails(root.Namespace, root.HelmReleaseName)
+	if err != nil {
+		return fmt.Errorf("could not load: %w", err)
+	}
+	if dbname != "" {
+		d.DBName = dbname
+	}
+
+	k8sClient := k8s.NewClient()
+	pod, err := getDumpPod(k8sClient)
+	if err != nil {
+		return fmt.Errorf("could not load: %w", err)
+	}
+
+	fmt.Printf("%s will be loaded into database %s in pod %s.\n", file, d.DBName, pod)
+	if clean {
+		fmt.Println("The objects in the dump will be dropped first.")
+	}
+	if !confirm {
+		utils.ConfirmAction()
+	}
+
+	restoreArgs := []string{"--no-owner"}
+	if clean {
+		restoreArgs = append(restoreArgs, "--clean", "--if-exists")
+	}
+	// post_restore runs even when pg_restore fails so the
+	// background jobs aren't left stopped, and keeps its exit code
+	script := passwordPrelude +
+		pgCommand(d, "psql", "-v", "ON_ERROR_STOP=1", "-o", "/dev/null", "-c", "SELECT timescaledb_pre_restore()") + " && { " +
+		pgCommand(d, "pg_restore", restoreArgs...) + "; rc=$?; " +
+		pgCommand(d, "psql", "-v", "ON_ERROR_STOP=1", "-o", "/dev/null", "-c", "SELECT timescaledb_post_restore()") + " && exit $rc; }"
+
+	fmt.Printf("Loading %s...\n", file)
+	stdin := io.MultiReader(strings.NewReader(d.Password+"\n"), f)
+	err = k8sClient.KubeExecCmdWithStreams(root.Namespace, pod, Container, script, stdin, os.Stdout, os.Stderr)
+	if err != nil {
+		return fmt.Errorf("could not load, pg_restore output is above: %w", err)
+	}
+
+	fmt.Printf("Loaded %s into database %s\n", file, d.DBName)
+	return nil
+}
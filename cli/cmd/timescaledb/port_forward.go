@@ -31,24 +31,20 @@ func timescaledbPortForward(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("could not port-forward TimescaleDB: %w", err)
 	}
 
-	if err := PortForwardTimescaleDB(port); err != nil {
-		return err
-	}
-
-	select {}
+	return common.RunPortForwards(&common.PortForwardTarget{Name: "TimescaleDB", Scheme: "postgres", Port: port, Forward: PortForwardTimescaleDB})
 }
 
-func PortForwardTimescaleDB(listenPort int) error {
+func PortForwardTimescaleDB(listenPort int) (*k8s.PortForward, error) {
 	k8sClient := k8s.NewClient()
 	podName, err := k8sClient.KubeGetPodName(root.Namespace, map[string]string{"release": root.HelmReleaseName, "role": "master"})
 	if err != nil {
-		return fmt.Errorf("could not port-forward TimescaleDB: %w", err)
+		return nil, fmt.Errorf("could not port-forward TimescaleDB: %w", err)
 	}
 
-	_, err = k8sClient.KubePortForwardPod(root.Namespace, podName, listenPort, common.FORWARD_PORT_TSDB)
+	pf, err := k8sClient.KubePortForwardPod(root.Namespace, podName, listenPort, common.FORWARD_PORT_TSDB)
 	if err != nil {
-		return fmt.Errorf("could not port-forward TimescaleDB: %w", err)
+		return nil, fmt.Errorf("could not port-forward TimescaleDB: %w", err)
 	}
 
-	return nil
+	return pf, nil
 }
@@ -0,0 +1,330 @@
+package timescaledb
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	root "github.com/timescale/tobs/cli/cmd"
+	"github.com/timescale/tobs/cli/cmd/common"
+	"github.com/timescale/tobs/cli/pkg/pgconn"
+)
+
+// timescaledbQueryCmd represents the timescaledb query command
+var timescaledbQueryCmd = &cobra.Command{
+	Use:   "query [sql]",
+	Short: "Runs SQL against the TimescaleDB database and prints the result",
+	Long: `Runs SQL against the TimescaleDB database and prints the result as a table,
+as CSV with --csv or as JSON/YAML with the global --output flag.
+
+The SQL is either the argument or read from the file given with --file, "-" reads it from stdin.
+The statements of a script run one after another in a single transaction and the result of each is printed.
+It runs as the superuser unless --user is set, the password of the user is then read from PGPASSWORD.`,
+	Example: `  tobs timescaledb query "SELECT * FROM prom_info.metric LIMIT 10"
+  tobs timescaledb query -f report.sql --csv --read-only`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: timescaledbQuery,
+}
+
+func init() {
+	Cmd.AddCommand(timescaledbQueryCmd)
+	timescaledbQueryCmd.Flags().StringP("file", "f", "", "file to read the SQL from, - reads from stdin")
+	timescaledbQueryCmd.Flags().StringP("user", "U", "", "database user to run the SQL as, defaults to the superuser")
+	timescaledbQueryCmd.Flags().StringP("dbname", "d", "", "database name to connect to, defaults to dbname from the helm release")
+	timescaledbQueryCmd.Flags().Bool("read-only", false, "run the SQL in a read-only transaction")
+	timescaledbQueryCmd.Flags().Bool("csv", false, "print the result as CSV")
+}
+
+// QueryResult is the result of a SQL statement
+type QueryResult struct {
+	Columns []string `json:"columns,omitempty"`
+	// Rows are the values of each row in the order of Columns
+	Rows [][]interface{} `json:"rows,omitempty"`
+	// CommandTag is set for statements which don't return rows
+	CommandTag string `json:"commandTag,omitempty"`
+
+	// text is the printable text of each value
+	text [][]string
+}
+
+func (q *QueryResult) PrintText(w io.Writer) error {
+	if len(q.Columns) == 0 {
+		_, err := fmt.Fprintln(w, q.CommandTag)
+		return err
+	}
+
+	table := tablewriter.NewWriter(w)
+	table.SetHeader(q.Columns)
+	table.SetAutoFormatHeaders(false)
+	table.AppendBulk(q.text)
+	table.Render()
+	_, err := fmt.Fprintf(w, "(%d rows)\n", len(q.text))
+	return err
+}
+
+// PrintCSV prints the header and rows as CSV
+func (q *QueryResult) PrintCSV(w io.Writer) error {
+	if len(q.Columns) == 0 {
+		return nil
+	}
+
+	cw := csv.NewWriter(w)
+	err := cw.Write(q.Columns)
+	if err != nil {
+		return err
+	}
+	err = cw.WriteAll(q.text)
+	if err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// QueryResults are the results of the statements of a script, in order
+type QueryResults []*QueryResult
+
+func (q QueryResults) PrintText(w io.Writer) error {
+	for i, r := range q {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		err := r.PrintText(w)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PrintCSV prints the results which return rows as CSV, separated by an empty line
+func (q QueryResults) PrintCSV(w io.Writer) error {
+	printed := false
+	for _, r := range q {
+		if len(r.Columns) == 0 {
+			continue
+		}
+		if printed {
+			fmt.Fprintln(w)
+		}
+		err := r.PrintCSV(w)
+		if err != nil {
+			return err
+		}
+		printed = true
+	}
+	return nil
+}
+
+// querier is implemented by both pgx.Conn and pgx.Tx
+type querier interface {
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+}
+
+func runQuery(ctx context.Context, q querier, sql string) (*QueryResult, error) {
+	rows, err := q.Query(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := &QueryResult{}
+	for _, f := range rows.FieldDescriptions() {
+		res.Columns = append(res.Columns, string(f.Name))
+	}
+
+	for rows.Next() {
+		values, err := rows.Values()
+		if err != nil {
+			return nil, err
+		}
+
+		row := make([]interface{}, len(values))
+		text := make([]string, len(values))
+		for i, v := range values {
+			// pgtype values such as numeric and interval
+			// convert to plain values through driver.Valuer
+			if valuer, ok := v.(driver.Valuer); ok {
+				v, err = valuer.Value()
+				if err != nil {
+					return nil, err
+				}
+			}
+			row[i] = v
+			text[i] = formatValue(v)
+		}
+		res.Rows = append(res.Rows, row)
+		res.text = append(res.text, text)
+	}
+	rows.Close()
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+
+	if len(res.Columns) == 0 {
+		res.CommandTag = rows.CommandTag().String()
+	}
+	return res, nil
+}
+
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case map[string]interface{}, []interface{}:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(b)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func readSQL(args []string, file string) (string, error) {
+	if len(args) == 1 && file != "" {
+		return "", fmt.Errorf("provide the SQL either as argument or with --file, not both")
+	}
+	if len(args) == 1 {
+		return args[0], nil
+	}
+	if file == "" {
+		return "", fmt.Errorf("provide the SQL as argument or with --file")
+	}
+
+	var data []byte
+	var err error
+	if file == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(file)
+	}
+	if err != nil {
+		return "", fmt.Errorf("could not read SQL from %s: %w", file, err)
+	}
+	return string(data), nil
+}
+
+func timescaledbQuery(cmd *cobra.Command, args []string) error {
+	file, err := cmd.Flags().GetString("file")
+	if err != nil {
+		return fmt.Errorf("could not run query: %w", err)
+	}
+
+	user, err := cmd.Flags().GetString("user")
+	if err != nil {
+		return fmt.Errorf("could not run query: %w", err)
+	}
+
+	dbname, err := cmd.Flags().GetString("dbname")
+	if err != nil {
+		return fmt.Errorf("could not run query: %w", err)
+	}
+
+	readOnly, err := cmd.Flags().GetBool("read-only")
+	if err != nil {
+		return fmt.Errorf("could not run query: %w", err)
+	}
+
+	asCSV, err := cmd.Flags().GetBool("csv")
+	if err != nil {
+		return fmt.Errorf("could not run query: %w", err)
+	}
+
+	if asCSV && root.OutputFormat != "" {
+		return fmt.Errorf("could not run query: --csv can't be used with --output")
+	}
+
+	sql, err := readSQL(args, file)
+	if err != nil {
+		return fmt.Errorf("could not run query: %w", err)
+	}
+	// the extended protocol pgx queries with runs a single statement at a time
+	statements := pgconn.SplitStatements(sql)
+	if len(statements) == 0 {
+		return fmt.Errorf("could not run query: no SQL statements to run")
+	}
+
+	d, err := common.GetSuperuserDBDetails(root.Namespace, root.HelmReleaseName)
+	if err != nil {
+		return fmt.Errorf("could not run query: %w", err)
+	}
+	if user != "" {
+		d.User = user
+		d.Password = os.Getenv("PGPASSWORD")
+		if d.Password == "" {
+			return fmt.Errorf("could not run query: set PGPASSWORD to the password of user %s", user)
+		}
+	}
+	if dbname != "" {
+		d.DBName = dbname
+	}
+
+	pool, err := d.OpenConnectionToDB()
+	if err != nil {
+		return fmt.Errorf("could not run query: %w", err)
+	}
+	defer pool.Close()
+
+	ctx := context.Background()
+	opts := pgx.TxOptions{}
+	if readOnly {
+		opts.AccessMode = pgx.ReadOnly
+	}
+	tx, err := pool.BeginTx(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("could not run query: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var results QueryResults
+	for i, stmt := range statements {
+		res, err := runQuery(ctx, tx, stmt)
+		if err != nil {
+			if len(statements) > 1 {
+				return fmt.Errorf("could not run query: statement %d: %w", i+1, err)
+			}
+			return fmt.Errorf("could not run query: %w", err)
+		}
+		results = append(results, res)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("could not run query: %w", err)
+	}
+
+	// a single statement prints its result alone
+	if len(results) == 1 {
+		if asCSV {
+			return results[0].PrintCSV(os.Stdout)
+		}
+		return common.PrintResult(results[0])
+	}
+	if asCSV {
+		return results.PrintCSV(os.Stdout)
+	}
+	return common.PrintResult(results)
+}
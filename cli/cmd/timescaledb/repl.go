@@ -0,0 +1,120 @@
+package timescaledb
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/jackc/pgx/v4"
+	root "github.com/timescale/tobs/cli/cmd"
+	"github.com/timescale/tobs/cli/cmd/common"
+	"github.com/timescale/tobs/cli/pkg/k8s"
+	"github.com/timescale/tobs/cli/pkg/pgconn"
+	"golang.org/x/term"
+)
+
+// REPLConnect runs the built-in SQL REPL against the database, port-forwarding
+// to the master pod unless the release uses an external database
+func REPLConnect(k8sClient k8s.Client, dbDetails *pgconn.DBDetails) error {
+	uri, err := common.GetTimescaleDBURI(k8sClient, root.Namespace, root.HelmReleaseName)
+	if err != nil {
+		return err
+	}
+
+	if uri == "" {
+		pf, err := PortForwardTimescaleDB(0)
+		if err != nil {
+			return fmt.Errorf("could not connect to TimescaleDB: %w", err)
+		}
+		defer pf.Stop()
+
+		password := dbDetails.Password
+		if password == "" {
+			password, err = readPassword(dbDetails.User)
+			if err != nil {
+				return fmt.Errorf("could not connect to TimescaleDB: %w", err)
+			}
+		}
+
+		u := url.URL{
+			Scheme: "postgres",
+			User:   url.UserPassword(dbDetails.User, password),
+			Host:   fmt.Sprintf("localhost:%d", pf.LocalPort()),
+			Path:   dbDetails.DBName,
+		}
+		uri = u.String()
+	}
+
+	ctx := context.Background()
+	conn, err := pgx.Connect(ctx, uri)
+	if err != nil {
+		return fmt.Errorf("could not connect to TimescaleDB: %w", err)
+	}
+	defer conn.Close(ctx)
+
+	return runREPL(ctx, conn, os.Stdin, os.Stdout)
+}
+
+func readPassword(user string) (string, error) {
+	fmt.Printf("Password for user %s: ", user)
+	password, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Println()
+	if err != nil {
+		return "", fmt.Errorf("could not read password: %w", err)
+	}
+	return string(password), nil
+}
+
+// runREPL executes the statements read from in, which end with a semicolon,
+// until \q or the end of the input. Statement errors are printed and don't end the session.
+func runREPL(ctx context.Context, conn *pgx.Conn, in io.Reader, out io.Writer) error {
+	dbName := conn.Config().Database
+	fmt.Fprintf(out, "Connected to %s as %s. Statements end with ';', \\q quits.\n", dbName, conn.Config().User)
+
+	scanner := bufio.NewScanner(in)
+	var statement strings.Builder
+	for {
+		if statement.Len() == 0 {
+			fmt.Fprintf(out, "%s=> ", dbName)
+		} else {
+			fmt.Fprintf(out, "%s-> ", dbName)
+		}
+
+		if !scanner.Scan() {
+			fmt.Fprintln(out)
+			return scanner.Err()
+		}
+
+		line := strings.TrimSpace(scanner.Text())
+		if statement.Len() == 0 && (line == `\q` || line == "quit" || line == "exit") {
+			return nil
+		}
+		if line == "" {
+			continue
+		}
+
+		statement.WriteString(line)
+		statement.WriteString("\n")
+		if !strings.HasSuffix(line, ";") {
+			continue
+		}
+
+		err := executeStatement(ctx, conn, statement.String(), out)
+		if err != nil {
+			fmt.Fprintf(out, "ERROR: %v\n", err)
+		}
+		statement.Reset()
+	}
+}
+
+func executeStatement(ctx context.Context, conn *pgx.Conn, sql string, out io.Writer) error {
+	res, err := runQuery(ctx, conn, sql)
+	if err != nil {
+		return err
+	}
+	return res.PrintText(out)
+}
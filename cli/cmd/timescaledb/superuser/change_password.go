@@ -45,7 +45,7 @@ func timescaledbChangePassword(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	_, err = pool.Exec(context.Background(), "ALTER USER "+dbDetails.User+" WITH PASSWORD '"+password+"'")
+	err = pgconn.AlterUserPassword(context.Background(), pool, dbDetails.User, password)
 	if err != nil {
 		err1 := updateDBPwdSecrets(k8sClient, dbDetails.SecretKey, dbDetails.User, dbDetails.Password)
 		if err1 != nil {
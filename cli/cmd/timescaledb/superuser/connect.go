@@ -21,6 +21,7 @@ var timescaledbConnectCmd = &cobra.Command{
 func init() {
 	superuserCmd.AddCommand(timescaledbConnectCmd)
 	timescaledbConnectCmd.Flags().BoolP("master", "m", false, "directly execute session on master node")
+	timescaledb.AddConnectModeFlag(timescaledbConnectCmd)
 }
 
 func Connect(cmd *cobra.Command, args []string) error {
@@ -29,11 +30,16 @@ func Connect(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("could not connect to TimescaleDB: %w", err)
 	}
 
+	mode, err := cmd.Flags().GetString("mode")
+	if err != nil {
+		return fmt.Errorf("could not connect to TimescaleDB: %w", err)
+	}
+
 	dbDetails, err := common.GetSuperuserDBDetails(root.Namespace, root.HelmReleaseName)
 	if err != nil {
 		return fmt.Errorf("could not get DB secret key from helm release: %w", err)
 	}
 
 	k8sClient := k8s.NewClient()
-	return timescaledb.PsqlConnect(k8sClient, dbDetails, master)
+	return timescaledb.Connect(k8sClient, dbDetails, master, mode)
 }
@@ -1,7 +1,6 @@
 package superuser
 
 import (
-	"fmt"
 	"github.com/spf13/cobra"
 	root "github.com/timescale/tobs/cli/cmd"
 	"github.com/timescale/tobs/cli/cmd/common"
@@ -25,6 +24,5 @@ func timescaledbGetPassword(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	fmt.Println(d.Password)
-	return nil
+	return common.PrintResult(&common.PasswordResult{Password: d.Password})
 }
@@ -0,0 +1,170 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/spf13/cobra"
+	root "github.com/timescale/tobs/cli/cmd"
+	"github.com/timescale/tobs/cli/cmd/common"
+	"github.com/timescale/tobs/cli/pkg/k8s"
+	"github.com/timescale/tobs/cli/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// userCreateCmd represents the timescaledb user create command
+var userCreateCmd = &cobra.Command{
+	Use:   "create <user>",
+	Short: "Creates a database user with Promscale roles",
+	Long: `Creates a login role in the database and grants it the given Promscale roles.
+The password is generated unless --password-stdin is set. With --secret the credentials
+are stored in a Kubernetes secret, otherwise the generated password is printed.`,
+	Example: `  tobs timescaledb user create grafana-team --role prom_reader --secret grafana-team-db`,
+	Args:    cobra.ExactArgs(1),
+	RunE:    userCreate,
+}
+
+func init() {
+	userCmd.AddCommand(userCreateCmd)
+	userCreateCmd.Flags().StringSliceP("role", "r", []string{"prom_reader"}, "Promscale roles to grant, one or more of: prom_reader, prom_writer, prom_admin, prom_maintenance")
+	userCreateCmd.Flags().Bool("password-stdin", false, "read the password from stdin instead of generating it")
+	userCreateCmd.Flags().String("secret", "", "name of the Kubernetes secret to store the credentials in")
+}
+
+func userCreate(cmd *cobra.Command, args []string) error {
+	user := args[0]
+
+	roles, err := cmd.Flags().GetStringSlice("role")
+	if err != nil {
+		return fmt.Errorf("could not create user: %w", err)
+	}
+
+	passwordStdin, err := cmd.Flags().GetBool("password-stdin")
+	if err != nil {
+		return fmt.Errorf("could not create user: %w", err)
+	}
+
+	secretName, err := cmd.Flags().GetString("secret")
+	if err != nil {
+		return fmt.Errorf("could not create user: %w", err)
+	}
+
+	err = validateRoles(roles)
+	if err != nil {
+		return fmt.Errorf("could not create user: %w", err)
+	}
+
+	var password string
+	if passwordStdin {
+		password, err = utils.ReadPassword(os.Stdin)
+	} else {
+		password, err = utils.GeneratePassword(32)
+	}
+	if err != nil {
+		return fmt.Errorf("could not create user: %w", err)
+	}
+
+	k8sClient := k8s.NewClient()
+	if secretName != "" {
+		exists, err := k8sClient.CheckSecretExists(secretName, root.Namespace)
+		if err != nil {
+			return fmt.Errorf("could not create user: %w", err)
+		}
+		if exists {
+			return fmt.Errorf("could not create user: secret %s already exists", secretName)
+		}
+	}
+
+	d, err := common.GetSuperuserDBDetails(root.Namespace, root.HelmReleaseName)
+	if err != nil {
+		return fmt.Errorf("could not create user: %w", err)
+	}
+
+	pool, err := d.OpenConnectionToDB()
+	if err != nil {
+		return fmt.Errorf("could not create user: %w", err)
+	}
+	defer pool.Close()
+
+	ctx := context.Background()
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("could not create user: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	// utility statements don't take parameters, so
+	// the server quotes the name and password instead
+	var createRole string
+	err = tx.QueryRow(ctx, "SELECT format('CREATE ROLE %I WITH LOGIN PASSWORD %L', $1::TEXT, $2::TEXT)", user, password).Scan(&createRole)
+	if err != nil {
+		return fmt.Errorf("could not create user: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, createRole)
+	if err != nil {
+		return fmt.Errorf("could not create user: %w", err)
+	}
+
+	err = grantRoles(ctx, tx, user, roles)
+	if err != nil {
+		return fmt.Errorf("could not create user: %w", err)
+	}
+
+	// the secret is created before committing so a failure leaves no user behind
+	if secretName != "" {
+		err = k8sClient.CreateSecret(formUserSecret(secretName, user, password))
+		if err != nil {
+			return fmt.Errorf("could not create user: %w", err)
+		}
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		if secretName != "" {
+			err1 := k8sClient.DeleteSecret(secretName, root.Namespace)
+			if err1 != nil {
+				fmt.Printf("failed to delete secret %s after the user creation failed %v\n", secretName, err1)
+			}
+		}
+		return fmt.Errorf("could not create user: %w", err)
+	}
+
+	fmt.Printf("Created user %s with roles %v\n", user, roles)
+	if secretName != "" {
+		fmt.Printf("Credentials are stored in secret %s\n", secretName)
+	} else if !passwordStdin {
+		fmt.Printf("Password: %s\n", password)
+	}
+	return nil
+}
+
+func grantRoles(ctx context.Context, tx pgx.Tx, user string, roles []string) error {
+	for _, r := range roles {
+		_, err := tx.Exec(ctx, "GRANT "+pgx.Identifier{r}.Sanitize()+" TO "+pgx.Identifier{user}.Sanitize())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formUserSecret(name, user, password string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: root.Namespace,
+			Labels:    map[string]string{"release": root.HelmReleaseName},
+		},
+		Type: corev1.SecretTypeBasicAuth,
+		StringData: map[string]string{
+			corev1.BasicAuthUsernameKey: user,
+			corev1.BasicAuthPasswordKey: password,
+		},
+	}
+}
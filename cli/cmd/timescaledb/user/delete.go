@@ -0,0 +1,142 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/spf13/cobra"
+	root "github.com/timescale/tobs/cli/cmd"
+	"github.com/timescale/tobs/cli/cmd/common"
+	"github.com/timescale/tobs/cli/pkg/helm"
+	"github.com/timescale/tobs/cli/pkg/k8s"
+	"github.com/timescale/tobs/cli/pkg/pgconn"
+	"github.com/timescale/tobs/cli/pkg/utils"
+)
+
+// userDeleteCmd represents the timescaledb user delete command
+var userDeleteCmd = &cobra.Command{
+	Use:   "delete <user>",
+	Short: "Deletes a database user",
+	Long: `Deletes a database user. The users the release connects as, the superuser,
+the Promscale user and the Patroni users of the credentials secret, can't be deleted.`,
+	Args: cobra.ExactArgs(1),
+	RunE: userDelete,
+}
+
+func init() {
+	userCmd.AddCommand(userDeleteCmd)
+	userDeleteCmd.Flags().String("secret", "", "name of the Kubernetes secret with the credentials of the user to delete as well")
+	userDeleteCmd.Flags().BoolP("confirm", "y", false, "Confirm the deletion without prompting")
+}
+
+func userDelete(cmd *cobra.Command, args []string) error {
+	user := args[0]
+
+	secretName, err := cmd.Flags().GetString("secret")
+	if err != nil {
+		return fmt.Errorf("could not delete user: %w", err)
+	}
+
+	confirm, err := cmd.Flags().GetBool("confirm")
+	if err != nil {
+		return fmt.Errorf("could not delete user: %w", err)
+	}
+
+	d, err := common.GetSuperuserDBDetails(root.Namespace, root.HelmReleaseName)
+	if err != nil {
+		return fmt.Errorf("could not delete user: %w", err)
+	}
+	protected, err := releaseRoles(d)
+	if err != nil {
+		return fmt.Errorf("could not delete user: %w", err)
+	}
+	if role, ok := protected[user]; ok {
+		return fmt.Errorf("could not delete user: %s is the %s of the release", user, role)
+	}
+
+	if !confirm {
+		fmt.Printf("User %s will be deleted.\n", user)
+		utils.ConfirmAction()
+	}
+
+	pool, err := d.OpenConnectionToDB()
+	if err != nil {
+		return fmt.Errorf("could not delete user: %w", err)
+	}
+	defer pool.Close()
+
+	_, err = pool.Exec(context.Background(), "DROP ROLE "+pgx.Identifier{user}.Sanitize())
+	if err != nil {
+		return fmt.Errorf("could not delete user: %w", err)
+	}
+
+	if secretName != "" {
+		k8sClient := k8s.NewClient()
+		err = k8sClient.DeleteSecret(secretName, root.Namespace)
+		if err != nil {
+			return fmt.Errorf("could not delete secret of user %s: %w", user, err)
+		}
+	}
+
+	fmt.Printf("Deleted user %s\n", user)
+	return nil
+}
+
+// releaseRoles returns the roles the secrets of the release reference, which the
+// stack connects as, mapped to what they're used for
+func releaseRoles(d *pgconn.DBDetails) (map[string]string, error) {
+	roles := map[string]string{d.User: "superuser"}
+
+	promscaleUser, err := d.PromscaleUser()
+	if err != nil {
+		return nil, fmt.Errorf("could not get the Promscale user: %w", err)
+	}
+	roles[promscaleUser] = "Promscale user"
+
+	enabled, err := common.IsTimescaleDBEnabled(root.HelmReleaseName, root.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	// an external database has no Patroni credentials
+	if !enabled {
+		return roles, nil
+	}
+
+	k8sClient := k8s.NewClient()
+	secret, err := k8sClient.KubeGetSecret(root.Namespace, root.HelmReleaseName+"-credentials")
+	if err != nil {
+		return nil, fmt.Errorf("could not get the Patroni credentials: %w", err)
+	}
+	for key := range secret.Data {
+		if !strings.HasPrefix(key, "PATRONI_") || !strings.HasSuffix(key, "_PASSWORD") {
+			continue
+		}
+		switch name := strings.TrimSuffix(strings.TrimPrefix(key, "PATRONI_"), "_PASSWORD"); key {
+		case common.DBSuperUserSecretKey:
+			// the superuser is already protected
+		case common.DBReplicationSecretKey:
+			replicationUser, err := replicationUser()
+			if err != nil {
+				return nil, err
+			}
+			roles[replicationUser] = "Patroni replication user"
+		default:
+			roles[name] = "Patroni " + name + " user"
+		}
+	}
+
+	return roles, nil
+}
+
+// replicationUser returns the user Patroni replicates as
+func replicationUser() (string, error) {
+	helmClient := helm.NewClient(root.Namespace)
+	defer helmClient.Close()
+	user, err := helmClient.ExportValuesFieldFromRelease(root.HelmReleaseName, []string{"timescaledb-single", "patroni", "postgresql", "authentication", "replication", "username"})
+	if err != nil {
+		return "", fmt.Errorf("could not get the Patroni replication user: %w", err)
+	}
+	return fmt.Sprint(user), nil
+}
@@ -0,0 +1,86 @@
+package user
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/spf13/cobra"
+	root "github.com/timescale/tobs/cli/cmd"
+	"github.com/timescale/tobs/cli/cmd/common"
+)
+
+// userGrantCmd represents the timescaledb user grant command
+var userGrantCmd = &cobra.Command{
+	Use:     "grant <user> <role>...",
+	Short:   "Grants Promscale roles to a database user",
+	Long:    `Grants Promscale roles to a database user, or revokes them with --revoke. The roles are one or more of: prom_reader, prom_writer, prom_admin, prom_maintenance.`,
+	Example: `  tobs timescaledb user grant grafana-team prom_writer`,
+	Args:    cobra.MinimumNArgs(2),
+	RunE:    userGrant,
+}
+
+func init() {
+	userCmd.AddCommand(userGrantCmd)
+	userGrantCmd.Flags().Bool("revoke", false, "revoke the roles instead of granting them")
+}
+
+func userGrant(cmd *cobra.Command, args []string) error {
+	user, roles := args[0], args[1:]
+
+	revoke, err := cmd.Flags().GetBool("revoke")
+	if err != nil {
+		return fmt.Errorf("could not grant roles: %w", err)
+	}
+
+	err = validateRoles(roles)
+	if err != nil {
+		return fmt.Errorf("could not grant roles: %w", err)
+	}
+
+	d, err := common.GetSuperuserDBDetails(root.Namespace, root.HelmReleaseName)
+	if err != nil {
+		return fmt.Errorf("could not grant roles: %w", err)
+	}
+
+	pool, err := d.OpenConnectionToDB()
+	if err != nil {
+		return fmt.Errorf("could not grant roles: %w", err)
+	}
+	defer pool.Close()
+
+	ctx := context.Background()
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("could not grant roles: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	if revoke {
+		for _, r := range roles {
+			_, err = tx.Exec(ctx, "REVOKE "+pgx.Identifier{r}.Sanitize()+" FROM "+pgx.Identifier{user}.Sanitize())
+			if err != nil {
+				return fmt.Errorf("could not revoke roles: %w", err)
+			}
+		}
+	} else {
+		err = grantRoles(ctx, tx, user, roles)
+		if err != nil {
+			return fmt.Errorf("could not grant roles: %w", err)
+		}
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("could not grant roles: %w", err)
+	}
+
+	if revoke {
+		fmt.Printf("Revoked roles %v from user %s\n", roles, user)
+	} else {
+		fmt.Printf("Granted roles %v to user %s\n", roles, user)
+	}
+	return nil
+}
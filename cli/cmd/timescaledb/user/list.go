@@ -0,0 +1,90 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	root "github.com/timescale/tobs/cli/cmd"
+	"github.com/timescale/tobs/cli/cmd/common"
+)
+
+// userListCmd represents the timescaledb user list command
+var userListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the database users with their roles",
+	Args:  cobra.ExactArgs(0),
+	RunE:  userList,
+}
+
+func init() {
+	userCmd.AddCommand(userListCmd)
+}
+
+// UserDetails is a database login role
+type UserDetails struct {
+	Name      string   `json:"name"`
+	Superuser bool     `json:"superuser"`
+	Roles     []string `json:"roles"`
+}
+
+// UserListResult is the result of the user list command
+type UserListResult struct {
+	Users []UserDetails `json:"users"`
+}
+
+func (u *UserListResult) PrintText(w io.Writer) error {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"User", "Superuser", "Roles"})
+	for _, user := range u.Users {
+		table.Append([]string{user.Name, strconv.FormatBool(user.Superuser), strings.Join(user.Roles, ", ")})
+	}
+	table.Render()
+	return nil
+}
+
+func userList(cmd *cobra.Command, args []string) error {
+	d, err := common.GetSuperuserDBDetails(root.Namespace, root.HelmReleaseName)
+	if err != nil {
+		return fmt.Errorf("could not list users: %w", err)
+	}
+
+	pool, err := d.OpenConnectionToDB()
+	if err != nil {
+		return fmt.Errorf("could not list users: %w", err)
+	}
+	defer pool.Close()
+
+	rows, err := pool.Query(context.Background(), `
+		SELECT r.rolname, r.rolsuper,
+			COALESCE(array_agg(g.rolname::TEXT ORDER BY g.rolname) FILTER (WHERE g.rolname IS NOT NULL), '{}')
+		FROM pg_roles r
+		LEFT JOIN pg_auth_members m ON m.member = r.oid
+		LEFT JOIN pg_roles g ON g.oid = m.roleid
+		WHERE r.rolcanlogin
+		GROUP BY r.rolname, r.rolsuper
+		ORDER BY r.rolname`)
+	if err != nil {
+		return fmt.Errorf("could not list users: %w", err)
+	}
+	defer rows.Close()
+
+	res := &UserListResult{}
+	for rows.Next() {
+		var u UserDetails
+		err = rows.Scan(&u.Name, &u.Superuser, &u.Roles)
+		if err != nil {
+			return fmt.Errorf("could not list users: %w", err)
+		}
+		res.Users = append(res.Users, u)
+	}
+	if rows.Err() != nil {
+		return fmt.Errorf("could not list users: %w", rows.Err())
+	}
+
+	return common.PrintResult(res)
+}
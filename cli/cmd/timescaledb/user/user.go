@@ -0,0 +1,47 @@
+package user
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	root "github.com/timescale/tobs/cli/cmd"
+	"github.com/timescale/tobs/cli/cmd/timescaledb"
+)
+
+// userCmd represents the timescaledb user command
+var userCmd = &cobra.Command{
+	Use:   "user",
+	Short: "Subcommand for TimescaleDB user operations",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		err := root.RootCmd.PersistentPreRunE(cmd, args)
+		if err != nil {
+			return fmt.Errorf("could not read global flag: %w", err)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	timescaledb.Cmd.AddCommand(userCmd)
+}
+
+// promscaleRoles are the roles predefined by Promscale which can be granted to users
+var promscaleRoles = []string{"prom_reader", "prom_writer", "prom_admin", "prom_maintenance"}
+
+func validateRoles(roles []string) error {
+	for _, r := range roles {
+		valid := false
+		for _, p := range promscaleRoles {
+			if r == p {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("unknown role %q, must be one of: %s", r, strings.Join(promscaleRoles, ", "))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package traces
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// retentionCmd represents the traces retention command
+var retentionCmd = &cobra.Command{
+	Use:   "retention",
+	Short: "Subcommand for operations that change trace retention period",
+}
+
+func init() {
+	tracesCmd.AddCommand(retentionCmd)
+}
@@ -0,0 +1,54 @@
+package traces
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	root "github.com/timescale/tobs/cli/cmd"
+	"github.com/timescale/tobs/cli/cmd/common"
+)
+
+// retentionGetCmd represents the traces retention get command
+var retentionGetCmd = &cobra.Command{
+	Use:   "get",
+	Short: "Gets data retention period in days for traces",
+	Args:  cobra.ExactArgs(0),
+	RunE:  retentionGet,
+}
+
+func init() {
+	retentionCmd.AddCommand(retentionGetCmd)
+}
+
+// RetentionResult is the retention period of traces
+type RetentionResult struct {
+	RetentionDays int `json:"retentionDays"`
+}
+
+func (r *RetentionResult) PrintText(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "Retention period for traces: %d days\n", r.RetentionDays)
+	return err
+}
+
+func retentionGet(cmd *cobra.Command, args []string) error {
+	d, err := common.GetSuperuserDBDetails(root.Namespace, root.HelmReleaseName)
+	if err != nil {
+		return err
+	}
+
+	pool, err := d.OpenConnectionToDB()
+	if err != nil {
+		return fmt.Errorf("could not get trace retention period: %w", err)
+	}
+	defer pool.Close()
+
+	res := &RetentionResult{}
+	err = pool.QueryRow(context.Background(), "SELECT EXTRACT(day FROM ps_trace.get_trace_retention_period())").Scan(&res.RetentionDays)
+	if err != nil {
+		return fmt.Errorf("could not get trace retention period: %w", err)
+	}
+
+	return common.PrintResult(res)
+}
@@ -0,0 +1,49 @@
+package traces
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	root "github.com/timescale/tobs/cli/cmd"
+	"github.com/timescale/tobs/cli/cmd/common"
+)
+
+// DefaultRetentionDays is the retention period of traces Promscale is installed with.
+// Promscale has no function to reset the trace retention, reset sets it back to this.
+const DefaultRetentionDays = 30
+
+// retentionResetCmd represents the traces retention reset command
+var retentionResetCmd = &cobra.Command{
+	Use:   "reset",
+	Short: "Resets data retention period for traces to the default of 30 days",
+	Args:  cobra.ExactArgs(0),
+	RunE:  retentionReset,
+}
+
+func init() {
+	retentionCmd.AddCommand(retentionResetCmd)
+}
+
+func retentionReset(cmd *cobra.Command, args []string) error {
+	var err error
+
+	d, err := common.GetSuperuserDBDetails(root.Namespace, root.HelmReleaseName)
+	if err != nil {
+		return err
+	}
+
+	pool, err := d.OpenConnectionToDB()
+	if err != nil {
+		return fmt.Errorf("could not reset trace retention period: %w", err)
+	}
+	defer pool.Close()
+
+	fmt.Printf("Resetting retention period for traces back to the default of %d days\n", DefaultRetentionDays)
+	_, err = pool.Exec(context.Background(), "SELECT ps_trace.set_trace_retention_period(INTERVAL '1 day' * $1)", DefaultRetentionDays)
+	if err != nil {
+		return fmt.Errorf("could not reset trace retention period: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package traces
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	root "github.com/timescale/tobs/cli/cmd"
+	"github.com/timescale/tobs/cli/cmd/common"
+)
+
+// retentionSetCmd represents the traces retention set command
+var retentionSetCmd = &cobra.Command{
+	Use:   "set <days>",
+	Short: "Sets data retention period in days for traces",
+	Args:  cobra.ExactArgs(1),
+	RunE:  retentionSet,
+}
+
+func init() {
+	retentionCmd.AddCommand(retentionSetCmd)
+}
+
+func retentionSet(cmd *cobra.Command, args []string) error {
+	var err error
+
+	retention_period := args[0]
+
+	d, err := common.GetSuperuserDBDetails(root.Namespace, root.HelmReleaseName)
+	if err != nil {
+		return err
+	}
+
+	pool, err := d.OpenConnectionToDB()
+	if err != nil {
+		return fmt.Errorf("could not set trace retention period: %w", err)
+	}
+	defer pool.Close()
+
+	fmt.Printf("Setting retention period for traces to %v days\n", retention_period)
+	_, err = pool.Exec(context.Background(), "SELECT ps_trace.set_trace_retention_period(INTERVAL '1 day' * $1)", retention_period)
+	if err != nil {
+		return fmt.Errorf("could not set trace retention period: %w", err)
+	}
+
+	return nil
+}
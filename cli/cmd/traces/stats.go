@@ -0,0 +1,80 @@
+package traces
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	root "github.com/timescale/tobs/cli/cmd"
+	"github.com/timescale/tobs/cli/cmd/common"
+)
+
+// statsCmd represents the traces stats command
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Shows the number of rows and on-disk size of the tracing tables",
+	Long:  "Shows the number of rows and on-disk size of the tracing tables. Row counts are approximate to avoid scanning the tables.",
+	Args:  cobra.ExactArgs(0),
+	RunE:  stats,
+}
+
+func init() {
+	tracesCmd.AddCommand(statsCmd)
+}
+
+// tracing hypertables created by Promscale
+var tracingTables = []string{"span", "event", "link"}
+
+// TableStats is the row count and size of a tracing table
+type TableStats struct {
+	Table     string `json:"table"`
+	Rows      int64  `json:"rows"`
+	SizeBytes int64  `json:"sizeBytes"`
+	Size      string `json:"size"`
+}
+
+// StatsResult is the result of the traces stats command
+type StatsResult struct {
+	Tables []TableStats `json:"tables"`
+}
+
+func (s *StatsResult) PrintText(w io.Writer) error {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Table", "Rows", "Size"})
+	for _, t := range s.Tables {
+		table.Append([]string{t.Table, strconv.FormatInt(t.Rows, 10), t.Size})
+	}
+	table.Render()
+	return nil
+}
+
+func stats(cmd *cobra.Command, args []string) error {
+	d, err := common.GetSuperuserDBDetails(root.Namespace, root.HelmReleaseName)
+	if err != nil {
+		return err
+	}
+
+	pool, err := d.OpenConnectionToDB()
+	if err != nil {
+		return fmt.Errorf("could not get traces stats: %w", err)
+	}
+	defer pool.Close()
+
+	res := &StatsResult{}
+	for _, name := range tracingTables {
+		t := TableStats{Table: name}
+		err = pool.QueryRow(context.Background(),
+			"SELECT approximate_row_count($1::regclass), COALESCE(hypertable_size($1::regclass), 0)",
+			"_ps_trace."+name).Scan(&t.Rows, &t.SizeBytes)
+		if err != nil {
+			return fmt.Errorf("could not get traces stats for %v: %w", name, err)
+		}
+		t.Size = common.FormatBytes(t.SizeBytes)
+		res.Tables = append(res.Tables, t)
+	}
+
+	return common.PrintResult(res)
+}
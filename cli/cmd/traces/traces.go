@@ -0,0 +1,26 @@
+package traces
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	root "github.com/timescale/tobs/cli/cmd"
+)
+
+// tracesCmd represents the traces command
+var tracesCmd = &cobra.Command{
+	Use:   "traces",
+	Short: "Subcommand for traces operations",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		err := root.RootCmd.PersistentPreRunE(cmd, args)
+		if err != nil {
+			return fmt.Errorf("could not read global flag: %w", err)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	root.RootCmd.AddCommand(tracesCmd)
+}
@@ -0,0 +1,43 @@
+package upgrade
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/timescale/tobs/cli/pkg/migration"
+)
+
+// UpgradePlanResult is the result of the upgrade --plan command
+type UpgradePlanResult struct {
+	DeployedVersion string           `json:"deployedVersion"`
+	NewVersion      string           `json:"newVersion"`
+	Preflight       []PreflightCheck `json:"preflight"`
+	Steps           []migration.Step `json:"steps"`
+}
+
+func (p *UpgradePlanResult) PrintText(w io.Writer) error {
+	fmt.Fprintf(w, "Upgrade from %s to %s\n\nPreflight checks:\n", p.DeployedVersion, p.NewVersion)
+	checks := tablewriter.NewWriter(w)
+	checks.SetHeader([]string{"Check", "Status", "Message"})
+	for _, c := range p.Preflight {
+		checks.Append([]string{c.Name, c.Status, c.Message})
+	}
+	checks.Render()
+
+	if len(p.Steps) == 0 {
+		_, err := fmt.Fprintln(w, "\nNo steps besides the helm upgrade.")
+		return err
+	}
+
+	fmt.Fprintln(w, "\nSteps besides the helm upgrade, in order:")
+	steps := tablewriter.NewWriter(w)
+	steps.SetHeader([]string{"#", "Kind", "Description", "Resources"})
+	steps.SetAutoWrapText(false)
+	for i, s := range p.Steps {
+		steps.Append([]string{fmt.Sprint(i + 1), s.Kind, s.Description, strings.Join(s.Resources, "\n")})
+	}
+	steps.Render()
+	return nil
+}
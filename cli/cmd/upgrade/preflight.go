@@ -0,0 +1,196 @@
+package upgrade
+
+import (
+	"fmt"
+	"strings"
+
+	root "github.com/timescale/tobs/cli/cmd"
+	"github.com/timescale/tobs/cli/cmd/common"
+	"github.com/timescale/tobs/cli/pkg/k8s"
+	"github.com/timescale/tobs/cli/pkg/migration"
+	"helm.sh/helm/v3/pkg/chartutil"
+	errors2 "k8s.io/apimachinery/pkg/api/errors"
+)
+
+const (
+	CheckPassed  = "passed"
+	CheckFailed  = "failed"
+	CheckSkipped = "skipped"
+
+	// minFreeSpacePercent is the free space each PVC needs for the upgrade, as the
+	// new versions can migrate data and write more WAL while they catch up
+	minFreeSpacePercent = 10
+)
+
+// PreflightCheck is the result of a check run before any change of the upgrade
+type PreflightCheck struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+func failedChecks(checks []PreflightCheck) []string {
+	var failed []string
+	for _, c := range checks {
+		if c.Status == CheckFailed {
+			failed = append(failed, fmt.Sprintf("%s: %s", c.Name, c.Message))
+		}
+	}
+	return failed
+}
+
+// preflightChecks checks the cluster can take the planned upgrade
+func (c *upgradeSpec) preflightChecks(kubeVersion string) []PreflightCheck {
+	return []PreflightCheck{
+		c.checkClusterVersion(kubeVersion),
+		c.checkFreeSpace(),
+		c.checkCRDOwnership(),
+	}
+}
+
+func (c *upgradeSpec) checkClusterVersion(constraint string) PreflightCheck {
+	check := PreflightCheck{Name: "cluster version"}
+	version, err := c.k8sClient.GetServerVersion()
+	if err != nil {
+		check.Status = CheckFailed
+		check.Message = fmt.Sprintf("could not get the Kubernetes version: %v", err)
+		return check
+	}
+
+	if constraint == "" {
+		check.Status = CheckPassed
+		check.Message = fmt.Sprintf("Kubernetes %s, the chart has no version constraint", version)
+		return check
+	}
+	if !chartutil.IsCompatibleRange(constraint, version) {
+		check.Status = CheckFailed
+		check.Message = fmt.Sprintf("Kubernetes %s doesn't meet the chart constraint %s", version, constraint)
+		return check
+	}
+	check.Status = CheckPassed
+	check.Message = fmt.Sprintf("Kubernetes %s meets %s", version, constraint)
+	return check
+}
+
+func (c *upgradeSpec) checkFreeSpace() PreflightCheck {
+	check := PreflightCheck{Name: "free PVC space"}
+	pods, err := c.releasePods()
+	if err != nil {
+		check.Status = CheckFailed
+		check.Message = err.Error()
+		return check
+	}
+	all, err := c.k8sClient.GetPVCVolumeStats(root.Namespace)
+	if err != nil {
+		// the kubelet stats can be unreachable by the user, which isn't a reason to stop
+		check.Status = CheckSkipped
+		check.Message = fmt.Sprintf("could not get the volume usage: %v", err)
+		return check
+	}
+	// other workloads of the namespace aren't touched by the upgrade
+	var stats []*k8s.VolumeStats
+	for _, s := range all {
+		if pods[s.Pod] {
+			stats = append(stats, s)
+		}
+	}
+	if len(stats) == 0 {
+		check.Status = CheckSkipped
+		check.Message = "no mounted PVCs of the release found"
+		return check
+	}
+
+	var low []string
+	for _, s := range stats {
+		if s.FreePercent() < minFreeSpacePercent {
+			low = append(low, fmt.Sprintf("%s %.1f%% free (%s)", s.PVCName, s.FreePercent(), common.FormatBytes(int64(s.AvailableBytes))))
+		}
+	}
+	if len(low) > 0 {
+		check.Status = CheckFailed
+		check.Message = fmt.Sprintf("less than %d%% free, expand them with tobs volume expand: %s", minFreeSpacePercent, strings.Join(low, ", "))
+		return check
+	}
+	check.Status = CheckPassed
+	check.Message = fmt.Sprintf("%d PVCs have at least %d%% free", len(stats), minFreeSpacePercent)
+	return check
+}
+
+// releasePods returns the names of the pods of the release's components
+func (c *upgradeSpec) releasePods() (map[string]bool, error) {
+	names := make(map[string]bool)
+	for _, component := range common.GetComponents(root.HelmReleaseName, root.Namespace) {
+		pods, err := c.k8sClient.KubeGetPods(component.Namespace, component.Labels)
+		if err != nil {
+			return nil, fmt.Errorf("could not get the %s pods: %w", component.Name, err)
+		}
+		for _, pod := range pods {
+			names[pod.Name] = true
+		}
+	}
+	return names, nil
+}
+
+// checkCRDOwnership checks no other helm release owns the CRDs the upgrade applies,
+// as applying them would take them over from the other release
+func (c *upgradeSpec) checkCRDOwnership() PreflightCheck {
+	check := PreflightCheck{Name: "CRD ownership"}
+	crds := migration.CRDs(c.steps)
+	if !c.skipCrds {
+		chartCRDs, err := c.helmClient.GetChartCRDs(c.chartRef)
+		if err != nil {
+			check.Status = CheckFailed
+			check.Message = fmt.Sprintf("could not get the CRDs of the chart: %v", err)
+			return check
+		}
+		crds = appendMissing(crds, chartCRDs)
+	}
+	if len(crds) == 0 {
+		check.Status = CheckSkipped
+		check.Message = "no CRDs are applied"
+		return check
+	}
+
+	apiClient := k8s.NewAPIClient()
+	var owned []string
+	for _, name := range crds {
+		crd, err := apiClient.GetCRD(name)
+		if err != nil {
+			if errors2.IsNotFound(err) {
+				continue
+			}
+			check.Status = CheckFailed
+			check.Message = fmt.Sprintf("could not get CRD %s: %v", name, err)
+			return check
+		}
+
+		release := crd.Annotations["meta.helm.sh/release-name"]
+		namespace := crd.Annotations["meta.helm.sh/release-namespace"]
+		if release != "" && (release != root.HelmReleaseName || namespace != root.Namespace) {
+			owned = append(owned, fmt.Sprintf("%s by %s/%s", name, namespace, release))
+		}
+	}
+	if len(owned) > 0 {
+		check.Status = CheckFailed
+		check.Message = "owned by other helm releases: " + strings.Join(owned, ", ")
+		return check
+	}
+	check.Status = CheckPassed
+	check.Message = fmt.Sprintf("%d CRDs are not owned by other releases", len(crds))
+	return check
+}
+
+// appendMissing appends the names of add which aren't in names yet
+func appendMissing(names, add []string) []string {
+	seen := make(map[string]bool)
+	for _, name := range names {
+		seen[name] = true
+	}
+	for _, name := range add {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -14,13 +15,9 @@ import (
 	"github.com/timescale/tobs/cli/cmd/install"
 	"github.com/timescale/tobs/cli/pkg/helm"
 	"github.com/timescale/tobs/cli/pkg/k8s"
-	"github.com/timescale/tobs/cli/pkg/otel"
+	"github.com/timescale/tobs/cli/pkg/migration"
 	"github.com/timescale/tobs/cli/pkg/utils"
 	"gopkg.in/yaml.v2"
-	batchv1 "k8s.io/api/batch/v1"
-	v1 "k8s.io/api/core/v1"
-	errors2 "k8s.io/apimachinery/pkg/api/errors"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // upgradeCmd represents the upgrade command
@@ -39,6 +36,15 @@ func init() {
 	upgradeCmd.Flags().BoolP("same-chart", "", false, "Use the same helm chart do not upgrade helm chart but upgrade the existing chart with new values")
 	upgradeCmd.Flags().BoolP("confirm", "y", false, "Confirmation flag for upgrading")
 	upgradeCmd.Flags().BoolP("skip-crds", "", false, "Option to skip creating CRDs on upgrade")
+	upgradeCmd.Flags().BoolP("dry-run", "", false, "Render the manifests with the merged values without touching the cluster")
+	upgradeCmd.Flags().BoolP("diff", "", false, "On dry-run print a per resource diff against the deployed release instead of the rendered manifests")
+	upgradeCmd.Flags().BoolP("plan", "", false, "Print the preflight checks and every step the upgrade takes besides the helm upgrade without changing anything")
+	upgradeCmd.Flags().BoolP("skip-preflight", "", false, "Upgrade even if the preflight checks fail")
+	upgradeCmd.Flags().BoolP("atomic", "", false, "Roll the release back to the revision before the upgrade if the upgrade fails")
+	upgradeCmd.Flags().DurationP("timeout", "", 10*time.Minute, "Time to wait for the upgraded resources to be ready with --atomic")
+	upgradeCmd.Flags().StringP("version", "", "", "Option to provide tobs helm chart version to upgrade to, if not provided will upgrade to the latest tobs chart available")
+	upgradeCmd.Flags().BoolP("allow-downgrade", "", false, "Allow --version to be older than the deployed chart version, the migrations of the newer versions aren't reverted")
+	upgradeCmd.Flags().BoolP("stepwise", "", false, "Upgrade through the intermediate chart versions the migrations need when the version can't be upgraded to directly")
 }
 
 func upgrade(cmd *cobra.Command, args []string) error {
@@ -50,10 +56,16 @@ type upgradeSpec struct {
 	newChartVersion      string
 	skipCrds             bool
 	k8sClient            k8s.Client
+	helmClient           helm.Client
 	upgradeValues        string
 	chartRef             string
 	valuesFile           string
-	upgradeCertManager   bool
+	dryRun               bool
+	// plan records the steps of the migrations without taking them
+	plan       bool
+	steps      []migration.Step
+	migrations []migration.Migration
+	context    *migration.Context
 }
 
 func upgradeTobs(cmd *cobra.Command, args []string) error {
@@ -92,37 +104,125 @@ func upgradeTobs(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("could not install The Observability Stack: %w", err)
 	}
 
+	dryRun, err := cmd.Flags().GetBool("dry-run")
+	if err != nil {
+		return fmt.Errorf("couldn't get the dry-run flag value: %w", err)
+	}
+
+	showDiff, err := cmd.Flags().GetBool("diff")
+	if err != nil {
+		return fmt.Errorf("couldn't get the diff flag value: %w", err)
+	}
+
+	if showDiff && !dryRun {
+		return errors.New("--diff can only be used with --dry-run")
+	}
+
+	plan, err := cmd.Flags().GetBool("plan")
+	if err != nil {
+		return fmt.Errorf("couldn't get the plan flag value: %w", err)
+	}
+
+	skipPreflight, err := cmd.Flags().GetBool("skip-preflight")
+	if err != nil {
+		return fmt.Errorf("couldn't get the skip-preflight flag value: %w", err)
+	}
+
+	if plan && dryRun {
+		return errors.New("--plan can't be used with --dry-run")
+	}
+
+	atomic, err := cmd.Flags().GetBool("atomic")
+	if err != nil {
+		return fmt.Errorf("couldn't get the atomic flag value: %w", err)
+	}
+
+	timeout, err := cmd.Flags().GetDuration("timeout")
+	if err != nil {
+		return fmt.Errorf("couldn't get the timeout flag value: %w", err)
+	}
+
+	version, err := cmd.Flags().GetString("version")
+	if err != nil {
+		return fmt.Errorf("couldn't get the version flag value: %w", err)
+	}
+
+	allowDowngrade, err := cmd.Flags().GetBool("allow-downgrade")
+	if err != nil {
+		return fmt.Errorf("couldn't get the allow-downgrade flag value: %w", err)
+	}
+
+	stepwise, err := cmd.Flags().GetBool("stepwise")
+	if err != nil {
+		return fmt.Errorf("couldn't get the stepwise flag value: %w", err)
+	}
+
+	if version != "" && sameChart {
+		return errors.New("--version can't be used with --same-chart")
+	}
+
 	upgradeHelmSpec := &helm.ChartSpec{
 		ReleaseName: root.HelmReleaseName,
 		ChartName:   ref,
 		Namespace:   root.Namespace,
+		Version:     version,
 		ResetValues: reset,
 		ReuseValues: reuse,
+		DryRun:      dryRun,
+		Atomic:      atomic,
+		Wait:        atomic,
+	}
+	// without --atomic the upgrade doesn't wait for the resources
+	if atomic {
+		upgradeHelmSpec.Timeout = timeout
 	}
 
 	if file != "" {
 		upgradeHelmSpec.ValuesFiles = []string{file}
 	}
 
-	helmClient := helm.NewClient(root.Namespace)
+	// the client reads the metadata and values of the requested chart version
+	helmClient := helm.NewClientForChartVersion(root.Namespace, version)
 	defer helmClient.Close()
+
+	// add & update helm chart only if it's default chart
+	// if same-chart upgrade is disabled, before the chart
+	// lookup so the index has the requested version
+	if ref == utils.DEFAULT_CHART && !sameChart {
+		err = helmClient.AddOrUpdateChartRepo(utils.DEFAULT_REGISTRY_NAME, utils.REPO_LOCATION)
+		if err != nil {
+			return fmt.Errorf("failed to add & update tobs helm chart %v", err)
+		}
+	}
+
 	latestChart, err := helmClient.GetChartMetadata(ref)
 	if err != nil {
 		return err
 	}
+	// the chart found must be the requested version, local charts
+	// and repositories without it resolve to another version
+	if version != "" && latestChart.Version != version {
+		return fmt.Errorf("helm chart %s is version %s, not the requested version %s", ref, latestChart.Version, version)
+	}
 
 	deployedChart, err := helmClient.GetDeployedChartMetadata(root.HelmReleaseName, root.Namespace)
 	if err != nil {
 		if err.Error() != utils.ErrorTobsDeploymentNotFound(root.HelmReleaseName).Error() {
 			return err
 		} else {
+			if plan {
+				return fmt.Errorf("couldn't find the existing tobs deployment to plan the upgrade of")
+			}
 			fmt.Println("couldn't find the existing tobs deployment. Deploying tobs...")
-			if !confirm {
+			if !confirm && !dryRun {
 				utils.ConfirmAction()
 			}
 			s := install.InstallSpec{
 				ConfigFile: file,
 				Ref:        ref,
+				Version:    version,
+				DryRun:     dryRun,
+				ShowDiff:   showDiff,
 			}
 			err = s.InstallStack()
 			if err != nil {
@@ -132,15 +232,6 @@ func upgradeTobs(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// add & update helm chart only if it's default chart
-	// if same-chart upgrade is disabled
-	if ref == utils.DEFAULT_CHART && !sameChart {
-		err = helmClient.AddOrUpdateChartRepo(utils.DEFAULT_REGISTRY_NAME, utils.REPO_LOCATION)
-		if err != nil {
-			return fmt.Errorf("failed to add & update tobs helm chart %v", err)
-		}
-	}
-
 	lVersion, err := utils.ParseVersion(latestChart.Version, 3)
 	if err != nil {
 		return fmt.Errorf("failed to parse latest helm chart version %w", err)
@@ -151,8 +242,17 @@ func upgradeTobs(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to parse deployed helm chart version %w", err)
 	}
 
+	downgrade := version != "" && lVersion < dVersion
+	if downgrade {
+		if !allowDowngrade {
+			return fmt.Errorf("helm chart version %s is older than the deployed version %s, the migrations of the newer versions "+
+				"aren't reverted on downgrades. Use --allow-downgrade to downgrade anyway", latestChart.Version, deployedChart.Version)
+		}
+		fmt.Printf("WARNING: downgrading from helm chart version %s to %s, the migrations of the newer versions aren't reverted\n", deployedChart.Version, latestChart.Version)
+	}
+
 	var foundNewChart bool
-	if lVersion <= dVersion {
+	if lVersion <= dVersion && !downgrade {
 		dValues, err := helmClient.GetReleaseValues(root.HelmReleaseName)
 		if err != nil {
 			return err
@@ -185,14 +285,22 @@ func upgradeTobs(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	if foundNewChart {
-		fmt.Printf("Upgrading to latest helm chart version: %s\n", latestChart.Version)
-	} else {
-		fmt.Println("Upgrading the existing helm chart with values.yaml file")
+	// the migrations can need the release at intermediate versions
+	hops, err := migration.Hops(deployedChart.Version, latestChart.Version)
+	if err != nil {
+		return err
 	}
-
-	if !confirm {
-		utils.ConfirmAction()
+	// the plan lists the intermediate upgrades as steps
+	if len(hops) > 1 && !plan {
+		intermediate := strings.Join(migration.IntermediateVersions(hops), ", ")
+		if !stepwise {
+			return fmt.Errorf("upgrade from version %s to %s can't be done directly, it needs step-wise upgrades through version %s. "+
+				"Upgrade with --stepwise or with --version to each of them", deployedChart.Version, latestChart.Version, intermediate)
+		}
+		if dryRun {
+			return fmt.Errorf("--dry-run can't render step-wise upgrades, dry-run the upgrade to version %s", hops[0].Version)
+		}
+		fmt.Printf("Upgrading step-wise through helm chart version %s\n", intermediate)
 	}
 
 	upgradeDetails := &upgradeSpec{
@@ -200,354 +308,197 @@ func upgradeTobs(cmd *cobra.Command, args []string) error {
 		newChartVersion:      latestChart.Version,
 		skipCrds:             skipCrds,
 		k8sClient:            k8s.NewClient(),
+		helmClient:           helmClient,
 		chartRef:             ref,
 		valuesFile:           file,
+		dryRun:               dryRun,
 	}
 
-	err = upgradeDetails.UpgradePathBasedOnVersion()
-	if err != nil {
-		return err
-	}
-	upgradeHelmSpec.ValuesYaml = upgradeDetails.upgradeValues
-
-	helmClient = helm.NewClient(root.Namespace)
-	_, err = helmClient.InstallOrUpgradeChart(context.Background(), upgradeHelmSpec)
-	if err != nil {
-		return fmt.Errorf("failed to upgrade %w", err)
-	}
-
-	// upgrade cert-manager post upgrade process as
-	// helm diff tries to evaluate resources with required APIVersions
-	// upgrading cert-manager prior to helm upgrade prompts the below error
-	//
-	// Error: failed to upgrade current release manifest contains removed kubernetes api(s)
-	// for this kubernetes version and it is therefore unable to build the kubernetes objects for performing the diff.
-	// error from kubernetes: [unable to recognize "": no matches for kind "Certificate" in version "cert-manager.io/v1alpha2",
-	// unable to recognize "": no matches for kind "Issuer" in version "cert-manager.io/v1alpha2"]
-	//
-	// This is expected as upgrade CM before helm upgrade doesn't support
-	// he deprecated API's tha helm expects to have.
-	if upgradeDetails.upgradeCertManager {
-		err = otel.UpgradeCertManager()
+	// plan the migrations first so the preflight checks
+	// fail before the migrations change anything in the cluster
+	if plan || !dryRun {
+		planner := *upgradeDetails
+		planner.plan = true
+		err = planner.planHops(hops)
 		if err != nil {
 			return err
 		}
-	}
-
-	fmt.Printf("Successfully upgraded %s to version: %s\n", root.HelmReleaseName, latestChart.Version)
-	return nil
-}
 
-func (c *upgradeSpec) UpgradePathBasedOnVersion() error {
-	nVersion, err := utils.ParseVersion(c.newChartVersion, 3)
-	if err != nil {
-		return fmt.Errorf("failed to parse latest helm chart version %w", err)
-	}
-
-	dVersion, err := utils.ParseVersion(c.deployedChartVersion, 3)
-	if err != nil {
-		return fmt.Errorf("failed to parse deployed helm chart version %w", err)
-	}
+		var checks []PreflightCheck
+		if !skipPreflight {
+			checks = planner.preflightChecks(latestChart.KubeVersion)
+		}
+		failed := failedChecks(checks)
+
+		if plan {
+			err = common.PrintResult(&UpgradePlanResult{
+				DeployedVersion: deployedChart.Version,
+				NewVersion:      latestChart.Version,
+				Preflight:       checks,
+				Steps:           planner.steps,
+			})
+			if err != nil {
+				return err
+			}
+			if len(failed) > 0 {
+				return fmt.Errorf("%d preflight checks failed", len(failed))
+			}
+			return nil
+		}
 
-	version0_2_2, err := utils.ParseVersion("0.2.2", 3)
-	if err != nil {
-		return fmt.Errorf("failed to parse 0.2.2 version %w", err)
+		if len(failed) > 0 {
+			return fmt.Errorf("preflight checks failed, see tobs upgrade --plan or skip them with --skip-preflight:\n  %s", strings.Join(failed, "\n  "))
+		}
 	}
 
-	version0_4_0, err := utils.ParseVersion(utils.Version_040, 3)
-	if err != nil {
-		return fmt.Errorf("failed to parse 0.4.0 version %w", err)
+	if foundNewChart && version != "" {
+		fmt.Printf("Upgrading to helm chart version: %s\n", latestChart.Version)
+	} else if foundNewChart {
+		fmt.Printf("Upgrading to latest helm chart version: %s\n", latestChart.Version)
+	} else {
+		fmt.Println("Upgrading the existing helm chart with values.yaml file")
 	}
 
-	version0_8_0, err := utils.ParseVersion("0.8.0", 3)
-	if err != nil {
-		return fmt.Errorf("failed to parse 0.8.0 version %w", err)
+	if !confirm && !dryRun {
+		utils.ConfirmAction()
 	}
 
-	// kube-prometheus is introduced on tobs >= 0.4.0 release
-	// so create CRDs if version >= 0.4.0 and only create CRDs
-	// if version change is noticed in upgrades...
-	if nVersion >= version0_4_0 && dVersion <= version0_4_0 && nVersion != dVersion {
-		if !c.skipCrds {
-			// Kube-Prometheus CRDs
-			err = c.applyCRDS(kubePrometheusCRDs)
-			if err != nil {
-				return err
-			}
-		}
-
-		prometheusNodeExporter := root.HelmReleaseName + "-prometheus-node-exporter"
-		err = c.k8sClient.DeleteDaemonset(prometheusNodeExporter, root.Namespace)
-		if err != nil {
-			ok := errors2.IsNotFound(err)
-			if !ok {
-				return fmt.Errorf("failed to delete %s daemonset %v", prometheusNodeExporter, err)
-			}
-		}
-		err = c.k8sClient.KubeDeleteService(root.Namespace, prometheusNodeExporter)
+	// record the release before the migrations change the cluster
+	// so a failed upgrade can be rolled back to it
+	var restore *restorePoint
+	if atomic && !dryRun {
+		restore, err = newRestorePoint(helmClient)
 		if err != nil {
-			ok := errors2.IsNotFound(err)
-			if !ok {
-				return fmt.Errorf("failed to delete %s service %v", prometheusNodeExporter, err)
-			}
-		}
-
-		if dVersion < version0_4_0 {
-			err = c.persistPrometheusDataDuringUpgrade()
-			if err != nil {
-				return err
-			}
+			return err
 		}
 	}
 
-	switch {
-	// The below case if for upgrade from any versions <= 0.2.2 to greater versions
-	case dVersion <= version0_2_2 && nVersion > version0_2_2:
-		return fmt.Errorf("upgrade from version below 0.2.2 is no longer supported in this tobs verison. Please use older tobs binary to do a step-by-step upgrade")
-	case dVersion < version0_8_0:
-		err = c.upgradeTo08X()
-		if err != nil {
-			return fmt.Errorf("failed to perform upgrade path to 0.8.0 %v", err)
+	deployedVersion := deployedChart.Version
+	for i, hop := range hops {
+		hopDetails := *upgradeDetails
+		hopDetails.deployedChartVersion = deployedVersion
+		hopDetails.newChartVersion = hop.Version
+		hopSpec := *upgradeHelmSpec
+
+		intermediate := i < len(hops)-1
+		if intermediate {
+			fmt.Printf("Upgrading to intermediate helm chart version: %s\n", hop.Version)
+			// the values file is for the requested version
+			hopDetails.valuesFile = ""
+			hopSpec.Version = hop.Version
+			hopSpec.ValuesFiles = nil
 		}
 
-	default:
-		// if the upgrade doesn't match the above condition
-		// that means we do not have an upgrade path for the base version to new version
-		// Note: This is helpful when someone wants to upgrade with just values.yaml (not between versions)
-		return nil
+		err = hopDetails.upgradeHop(hop, &hopSpec, intermediate, restore, i == 0, showDiff)
+		if err != nil || dryRun {
+			return err
+		}
+		deployedVersion = hop.Version
 	}
 
+	fmt.Printf("Successfully upgraded %s to version: %s\n", root.HelmReleaseName, latestChart.Version)
 	return nil
 }
 
-func (c *upgradeSpec) upgradeTo08X() error {
-	helmClient := helm.NewClient(root.Namespace)
-	defer helmClient.Close()
-	releaseValues, err := helmClient.GetReleaseValues(root.HelmReleaseName)
-	if err != nil {
-		return err
+// upgradeHop migrates and upgrades the release to the version of the hop. The helm
+// clients of the hop are closed once it's done as closing restores HELM_NAMESPACE.
+func (c *upgradeSpec) upgradeHop(hop migration.Hop, spec *helm.ChartSpec, intermediate bool, restore *restorePoint, first, showDiff bool) error {
+	if intermediate {
+		hopClient := helm.NewClientForChartVersion(root.Namespace, hop.Version)
+		defer hopClient.Close()
+		c.helmClient = hopClient
 	}
 
-	// capture existing TimescaleDB secret
-	isTSDBEnabled, err := common.IsTimescaleDBEnabled(root.HelmReleaseName, root.Namespace)
+	err := c.migrate(hop.Migrations)
 	if err != nil {
-		return fmt.Errorf("failed to get is timescaledb enabled value %v", err)
-	}
-
-	var tsdbSecretValue string
-	if isTSDBEnabled {
-		tsdbSecret, err := c.k8sClient.KubeGetSecret(root.Namespace, root.HelmReleaseName+"-credentials")
-		if err != nil {
-			return fmt.Errorf("failed to get secret %v", err)
-		}
-		tsdbSecretValue = string(tsdbSecret.Data[common.DBSuperUserSecretKey])
+		return restore.rollback(err)
 	}
+	spec.ValuesYaml = c.upgradeValues
 
-	// Delete kube-state-metrics as per kube-prometheus upgrade guide
-	err = c.k8sClient.DeleteDeployment(map[string]string{"app.kubernetes.io/instance": root.HelmReleaseName,
-		"app.kubernetes.io/name": "kube-state-metrics"}, root.Namespace)
+	helmClient := helm.NewClient(root.Namespace)
+	defer helmClient.Close()
+	release, err := helmClient.InstallOrUpgradeChart(context.Background(), spec)
 	if err != nil {
-		return fmt.Errorf("failed to delete kube-state-metrics deployment %v", err)
+		// with --atomic helm already rolled the first hop back
+		if restore != nil && first {
+			return fmt.Errorf("upgrade failed and was rolled back to revision %d: %w", restore.revision, err)
+		}
+		if restore != nil {
+			return restore.rollback(err)
+		}
+		return fmt.Errorf("failed to upgrade %w", err)
 	}
 
-	// Delete the grafana-db job to re-run the job on upgrade
-	// and the db job includes changes to spec in 0.8.0 version
-	grafanaJob := root.HelmReleaseName + "-grafana-db"
-	err = c.k8sClient.DeleteJob(grafanaJob, root.Namespace)
-	if err != nil && !errors2.IsNotFound(err) {
-		return fmt.Errorf("failed to delete %s job %v", grafanaJob, err)
+	if c.dryRun {
+		return common.PrintDryRun(helmClient, release, showDiff)
 	}
 
-	// update Kube-Prometheus CRDs
-	err = c.applyCRDS(kubePrometheusCRDs)
-	if err != nil {
-		return err
-	}
+	// the helm upgrade succeeded, rolling it back would leave the old
+	// revision running against the changes the post-hooks already made
+	return c.postUpgrade()
+}
 
-	// delete timescaledbExternal section in values.yaml
-	var externalDBURI string
-	_, tsdbExternalExists := releaseValues["timescaledbExternal"]
-	if tsdbExternalExists {
-		timescaleDBExternal, ok := releaseValues["timescaledbExternal"].(map[string]interface{})
-		if !ok {
-			return fmt.Errorf("timescaledbExternal is not the expected type: %T", releaseValues["timescaledbExternal"])
+// planHops records the steps of the migrations of every hop without taking them
+func (c *upgradeSpec) planHops(hops []migration.Hop) error {
+	for i, hop := range hops {
+		err := c.migrate(hop.Migrations)
+		if err != nil {
+			return err
 		}
-		if timescaleDBExternal != nil {
-			isExternalTsdbenabled, ok := timescaleDBExternal["enabled"].(bool)
-			if !ok {
-				return fmt.Errorf("timescaledbExternal.enabled is not the expected type: %T", timescaleDBExternal["enabled"])
-			}
-			if isExternalTsdbenabled {
-				externalDBURI, ok = timescaleDBExternal["db_uri"].(string)
-				if !ok {
-					return fmt.Errorf("timescaledbExternal.db_uri is not the expected type: %T", timescaleDBExternal["db_uri"])
-				}
-			}
-			delete(releaseValues, "timescaledbExternal")
+		if i < len(hops)-1 {
+			c.context.Steps = append(c.context.Steps, migration.Step{
+				Kind:        migration.StepUpgrade,
+				Description: "helm upgrade to intermediate version " + hop.Version,
+			})
+			c.steps = c.context.Steps
 		}
 	}
+	return nil
+}
 
-	// refactor promscale section in values.yaml
-	var isTracingEnabled, ok bool
-	var promscaleValues map[string]interface{}
-	_, promscaleExists := releaseValues["promscale"]
-	if promscaleExists {
-		promscaleValues, ok = releaseValues["promscale"].(map[string]interface{})
-		if !ok {
-			return fmt.Errorf("promscale is not the expected type: %T", releaseValues["promscale"])
-		}
+// migrate runs the pre-hooks and values transforms of the migrations of a hop. The
+// values migrated by a previous call are migrated further, so a plan covers every hop.
+func (c *upgradeSpec) migrate(migrations []migration.Migration) error {
+	// without migrations the upgrade is just with values.yaml (not between versions)
+	if len(migrations) == 0 {
+		return nil
 	}
 
-	if !promscaleExists {
-		// promscale values is nil, construct the spec to
-		// assign db password/uri
-		releaseValues["promscale"] = make(map[string]interface{})
-		promscaleValues = releaseValues["promscale"].(map[string]interface{})
-		promscaleValues["connection"] = make(map[string]interface{})
-		connValues := promscaleValues["connection"].(map[string]interface{})
-		connValues["uri"] = externalDBURI
-		connValues["password"] = tsdbSecretValue
-	} else {
-		for k1, v1 := range promscaleValues {
-			if k1 == "tracing" {
-				traceMap, ok := v1.(map[string]interface{})
-				if !ok {
-					return fmt.Errorf("promscale.tracing is not the expected type: %T", v1)
-				}
-				isTracingEnabled, ok = traceMap["enabled"].(bool)
-				if !ok {
-					return fmt.Errorf("promscale.tracing.enabled is not the expected type: %T", traceMap["enabled"])
-				}
-
-				promscaleValues["openTelemetry"] = v1
-				// drop older tracing field which is no longer used
-				delete(promscaleValues, "tracing")
-			}
-
-			if k1 == "image" {
-				imageName, ok := v1.(string)
-				if !ok {
-					return fmt.Errorf("promscale.image is not the expected type: %T", v1)
-				}
-				// In tobs 0.7.0 release we hardcoded
-				// beta release if tracing is enabled, from 0.8.0 tobs
-				// release all default images will include out of the tracing support in
-				// all Promscale images.
-				if imageName == "timescale/promscale:0.7.0-beta.latest" {
-					delete(promscaleValues, "image")
-				}
-			}
-
-			if k1 == "args" {
-				aV, ok := v1.([]interface{})
-				if !ok {
-					return fmt.Errorf("promscale.args is not the expected type: %T", v1)
-				}
-				for in, value := range aV {
-					if value == "-otlp-grpc-server-listen-address=:9202" {
-						aV = append(aV[:in], aV[in+1:]...)
-					}
-					// if HA arg is found in Promscale
-					// change it to new HA arg.
-					if value == "--high-availability" {
-						aV[in] = "--metrics.high-availability"
-					}
-				}
-				promscaleValues["args"] = aV
-			}
-
-			if k1 == "connection" {
-				connectionValues, ok := v1.(map[string]interface{})
-				if !ok {
-					return fmt.Errorf("promscale.connection is not the expected type: %T", v1)
-				}
-
-				connectionValues["uri"] = externalDBURI
-
-				for k2, v2 := range connectionValues {
-					if k2 == "password" {
-						connectionValues["password"] = tsdbSecretValue
-					}
-
-					if k2 == "host" {
-						hostValues, ok := v2.(map[string]interface{})
-						if !ok {
-							return fmt.Errorf("promscale.connection.host is not the expected type: %T", v2)
-						}
-						hostValue := hostValues["nameTemplate"]
-						connectionValues["host"] = hostValue
-					}
-				}
-			}
-
-			if k1 == "service" {
-				promService, ok := v1.(map[string]interface{})
-				if !ok {
-					return fmt.Errorf("promscale.service is not the expected type: %T", v1)
-				}
-				lbValues := promService["loadBalancer"]
-				lb, ok := lbValues.(map[string]interface{})
-				if !ok {
-					return fmt.Errorf("promscale.service.loadBalancer is not the expected type: %T", lbValues)
-				}
-				lbEnabled := lb["enabled"]
-				if lbEnabled == "true" {
-					promService["type"] = "LoadBalancer"
-				} else {
-					promService["type"] = "ClusterIP"
-				}
-				delete(promService, "loadBalancer")
-			}
+	if c.context == nil {
+		releaseValues, err := c.helmClient.GetReleaseValues(root.HelmReleaseName)
+		if err != nil {
+			return err
 		}
-	}
 
-	if isTracingEnabled {
-		otelCol := otel.OtelCol{
+		c.context = &migration.Context{
 			ReleaseName: root.HelmReleaseName,
 			Namespace:   root.Namespace,
 			K8sClient:   c.k8sClient,
-			HelmClient:  helmClient,
-		}
-
-		// apply OpenTelemetry CRDs
-		err = c.k8sClient.ApplyManifests(otel.OpenTelemetryCRDs)
-		if err != nil {
-			return err
-		}
-		fmt.Println("Successfully created CRDs: ", reflect.ValueOf(otel.OpenTelemetryCRDs).MapKeys())
-
-		config, err := helmClient.ExportValuesFieldFromChart(c.chartRef, c.valuesFile, []string{"opentelemetryOperator", "collector", "config"})
-		if err != nil {
-			return err
-		}
-		otelColConfig, ok := config.(string)
-		if !ok {
-			return fmt.Errorf("opentelemetryOperator.collector.config is not the expected type: %T", config)
+			HelmClient:  c.helmClient,
+			ChartRef:    c.chartRef,
+			ValuesFile:  c.valuesFile,
+			SkipCRDs:    c.skipCrds,
+			Plan:        c.plan,
+			DryRun:      c.dryRun,
+			Values:      releaseValues,
 		}
+	}
+	c.migrations = migrations
 
-		err = otelCol.ValidateCertManager()
+	err := migration.PreUpgrade(c.context, migrations)
+	if err != nil {
+		return err
+	}
+	// the post-hooks only record their steps on plan
+	if c.plan {
+		err = migration.PostUpgrade(c.context, migrations)
 		if err != nil {
 			return err
 		}
-		c.upgradeCertManager = otelCol.UpgradeCM
-
-		if err = otelCol.DeleteDefaultOtelCollector(); err != nil {
-			return err
-		}
-
-		if err = otelCol.CreateDefaultCollector(otelColConfig); err != nil {
-			return err
-		}
-		// re-structure jaeger values
-		otelValues, ok := releaseValues["opentelemetryOperator"].(map[string]interface{})
-		if !ok {
-			return fmt.Errorf("opentelemetryOperator is not the expected type: %T", releaseValues["opentelemetryOperator"])
-		}
-		// delete jaegerPromscaleQuery as it's no-longer used in values.yaml
-		delete(otelValues, "jaegerPromscaleQuery")
 	}
+	c.steps = c.context.Steps
 
-	d, err := yaml.Marshal(&releaseValues)
+	d, err := yaml.Marshal(&c.context.Values)
 	if err != nil {
 		return fmt.Errorf("failed to marshal release values %v", err)
 	}
@@ -556,132 +507,69 @@ func (c *upgradeSpec) upgradeTo08X() error {
 	return nil
 }
 
-var (
-	// FIXME(paulfantom): if CRDs would contain label with version, we could deduct this value
-	// Needs https://github.com/prometheus-operator/prometheus-operator/issues/4344 to be completed
-	KubePrometheusCRDVersion     = "v0.56.2"
-	kubePrometheusCRDsPathPrefix = fmt.Sprintf("https://raw.githubusercontent.com/prometheus-operator/prometheus-operator/%s/example/prometheus-operator-crd/monitoring.coreos.com", KubePrometheusCRDVersion)
-	kubePrometheusCRDs           = map[string]string{
-		"alertmanagerconfigs.monitoring.coreos.com": fmt.Sprintf("%s_%s.yaml", kubePrometheusCRDsPathPrefix, "alertmanagerconfigs"),
-		"alertmanagers.monitoring.coreos.com":       fmt.Sprintf("%s_%s.yaml", kubePrometheusCRDsPathPrefix, "alertmanagers"),
-		"podmonitors.monitoring.coreos.com":         fmt.Sprintf("%s_%s.yaml", kubePrometheusCRDsPathPrefix, "podmonitors"),
-		"probes.monitoring.coreos.com":              fmt.Sprintf("%s_%s.yaml", kubePrometheusCRDsPathPrefix, "probes"),
-		"prometheuses.monitoring.coreos.com":        fmt.Sprintf("%s_%s.yaml", kubePrometheusCRDsPathPrefix, "prometheuses"),
-		"servicemonitors.monitoring.coreos.com":     fmt.Sprintf("%s_%s.yaml", kubePrometheusCRDsPathPrefix, "servicemonitors"),
-		"thanosrulers.monitoring.coreos.com":        fmt.Sprintf("%s_%s.yaml", kubePrometheusCRDsPathPrefix, "thanosrulers"),
-		"prometheusrules.monitoring.coreos.com":     fmt.Sprintf("%s_%s.yaml", kubePrometheusCRDsPathPrefix, "prometheusrules"),
+// postUpgrade runs the post-hooks of the migrations after the helm upgrade
+func (c *upgradeSpec) postUpgrade() error {
+	if c.context == nil {
+		return nil
 	}
-)
 
-func (c *upgradeSpec) applyCRDS(crds map[string]string) error {
-	err := c.k8sClient.ApplyManifests(crds)
+	taken := len(c.context.Steps)
+	err := migration.PostUpgrade(c.context, c.migrations)
 	if err != nil {
-		return fmt.Errorf("failed to apply manifest with error %v", err)
+		// the step failing is the last one recorded
+		failed := "run the post-upgrade steps"
+		if steps := c.context.Steps[taken:]; len(steps) > 0 {
+			failed = steps[len(steps)-1].Description
+		}
+		return fmt.Errorf("%s was upgraded to version %s but the post-upgrade step failed, the release isn't rolled back. "+
+			"Please %s manually and re-run tobs upgrade to complete the upgrade: %w", root.HelmReleaseName, c.newChartVersion, failed, err)
 	}
-
-	fmt.Println("Successfully created CRDs: ", reflect.ValueOf(crds).MapKeys())
 	return nil
 }
 
-func (c *upgradeSpec) persistPrometheusDataDuringUpgrade() error {
-	// scale down prometheus replicas to 0
-	fmt.Println("Migrating the underlying prometheus persistent volume to new prometheus instance...")
-	prometheus := root.HelmReleaseName + "-prometheus-server"
-	prometheusDeployment, err := c.k8sClient.GetDeployment(prometheus, root.Namespace)
+// restorePoint is the release revision and values recorded before an atomic upgrade
+type restorePoint struct {
+	helmClient helm.Client
+	revision   int
+	values     map[string]interface{}
+}
+
+func newRestorePoint(helmClient helm.Client) (*restorePoint, error) {
+	revision, err := helmClient.GetReleaseRevision(root.HelmReleaseName)
 	if err != nil {
-		return fmt.Errorf("failed to get %s %v", prometheus, err)
+		return nil, fmt.Errorf("could not record the release revision before the upgrade: %w", err)
 	}
 
-	fmt.Println("Scaling down prometheus instances to 0 replicas...")
-	var r int32 = 0
-	prometheusDeployment.Spec.Replicas = &r
-	err = c.k8sClient.UpdateDeployment(prometheusDeployment)
+	values, err := helmClient.GetReleaseValues(root.HelmReleaseName)
 	if err != nil {
-		return fmt.Errorf("failed to update %s %v", prometheus, err)
+		return nil, fmt.Errorf("could not record the release values before the upgrade: %w", err)
 	}
 
-	count := 0
-	for {
-		pods, err := c.k8sClient.KubeGetPods(root.Namespace, map[string]string{"app": "prometheus", "component": "server", "release": root.HelmReleaseName})
-		if err != nil {
-			return fmt.Errorf("unable to get pods from prometheus deployment %v", err)
-		}
-		if len(pods) == 0 {
-			break
-		}
+	return &restorePoint{helmClient: helmClient, revision: revision, values: values}, nil
+}
 
-		if count == 10 {
-			return fmt.Errorf("prometheus pod shutdown saves all in memory data to persistent volume, prometheus pod is taking too long to shut down... ")
-		}
-		count++
-		time.Sleep(time.Duration(count*10) * time.Second)
+// rollback rolls the release back to the recorded revision after the upgrade failed with cause.
+// Without a restore point (not an atomic upgrade) it returns cause as is.
+func (r *restorePoint) rollback(cause error) error {
+	if r == nil {
+		return cause
 	}
 
-	// update existing prometheus PV to persist data and create a new PVC so the
-	// new prometheus mounts to the created PVC which binds to older prometheus PV.
-	err = c.k8sClient.UpdatePVToNewPVC(prometheus, utils.PrometheusPVCName, root.Namespace, map[string]string{"prometheus": "tobs-kube-prometheus", "release": root.HelmReleaseName})
+	fmt.Printf("Upgrade failed, rolling %s back to revision %d\n", root.HelmReleaseName, r.revision)
+	err := r.helmClient.Rollback(root.HelmReleaseName, r.revision)
 	if err != nil {
-		return fmt.Errorf("failed to update prometheus persistent volume %v", err)
+		return fmt.Errorf("upgrade failed: %v, and the rollback to revision %d failed: %w", cause, r.revision, err)
 	}
 
-	// create job to update prometheus data directory permissions as the
-	// new prometheus expects the data dir to be owned by userid 1000.
-	fmt.Println("Create job to update prometheus data directory permissions...")
-	err = c.k8sClient.CreateJob(getJobForPrometheusDataPermissionChange(utils.PrometheusPVCName))
+	values, err := r.helmClient.GetReleaseValues(root.HelmReleaseName)
 	if err != nil {
-		return fmt.Errorf("failed to create job for prometheus data migration %v", err)
+		fmt.Printf("could not verify the values of the rolled back release: %v\n", err)
+	} else if !reflect.DeepEqual(values, r.values) {
+		fmt.Println("WARNING: the values of the rolled back release differ from the values before the upgrade")
 	}
 
-	return nil
+	return fmt.Errorf("upgrade failed and was rolled back to revision %d: %w", r.revision, cause)
 }
 
-func getJobForPrometheusDataPermissionChange(pvcName string) *batchv1.Job {
-	var backoff int32 = 3
-	return &batchv1.Job{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      utils.UpgradeJob_040,
-			Namespace: root.Namespace,
-			Labels:    map[string]string{"app": "tobs-upgrade", "release": root.HelmReleaseName},
-		},
-		Spec: batchv1.JobSpec{
-			BackoffLimit: &backoff,
-			Template: v1.PodTemplateSpec{
-				ObjectMeta: metav1.ObjectMeta{},
-				Spec: v1.PodSpec{
-					RestartPolicy: "OnFailure",
-					Containers: []v1.Container{
-						{
-							Name:            "upgrade-tobs",
-							Image:           "alpine",
-							ImagePullPolicy: v1.PullIfNotPresent,
-							Stdin:           true,
-							TTY:             true,
-							Command: []string{
-								"chown",
-								"1000:1000",
-								"-R",
-								"/data/",
-							},
-							VolumeMounts: []v1.VolumeMount{
-								{
-									Name:      "prometheus",
-									MountPath: "/data",
-								},
-							},
-						},
-					},
-					Volumes: []v1.Volume{
-						{
-							Name: "prometheus",
-							VolumeSource: v1.VolumeSource{
-								PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{
-									ClaimName: pvcName,
-								},
-							},
-						},
-					},
-				},
-			},
-		},
-	}
-}
+// KubePrometheusCRDVersion is the version of the Kube-Prometheus CRDs the migrations apply
+var KubePrometheusCRDVersion = migration.KubePrometheusCRDVersion
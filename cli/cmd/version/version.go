@@ -2,9 +2,11 @@ package version
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/spf13/cobra"
 	root "github.com/timescale/tobs/cli/cmd"
+	"github.com/timescale/tobs/cli/cmd/common"
 	"github.com/timescale/tobs/cli/pkg/helm"
 	"github.com/timescale/tobs/cli/pkg/utils"
 )
@@ -25,21 +27,44 @@ func init() {
 	versionCmd.Flags().BoolP("deployed-chart", "d", false, "Option to show deployed tobs helm chart version")
 }
 
+// VersionResult is the version of tobs CLI alongside the latest or the deployed helm chart version
+type VersionResult struct {
+	CLIVersion           string `json:"cliVersion"`
+	LatestChartVersion   string `json:"latestChartVersion,omitempty"`
+	DeployedChartVersion string `json:"deployedChartVersion,omitempty"`
+	DeployedChartError   string `json:"deployedChartError,omitempty"`
+}
+
+func (v *VersionResult) PrintText(w io.Writer) error {
+	var chartVersion string
+	switch {
+	case v.DeployedChartError != "":
+		chartVersion = v.DeployedChartError
+	case v.DeployedChartVersion != "":
+		chartVersion = fmt.Sprintf("deployed tobs helm chart version: %s", v.DeployedChartVersion)
+	default:
+		chartVersion = fmt.Sprintf("latest tobs helm chart version: %s", v.LatestChartVersion)
+	}
+
+	_, err := fmt.Fprintf(w, "Tobs CLI Version: %s, %s \n", v.CLIVersion, chartVersion)
+	return err
+}
+
 func version(cmd *cobra.Command, args []string) error {
 	d, err := cmd.Flags().GetBool("deployed-chart")
 	if err != nil {
 		return fmt.Errorf("could not get deployed tobs helm chart version: %w", err)
 	}
 
-	var chartVersion string
+	res := &VersionResult{CLIVersion: tobsVersion}
 	helmClient := helm.NewClient(root.Namespace)
 	defer helmClient.Close()
 	if d {
 		deployedChart, err := helmClient.GetDeployedChartMetadata(root.HelmReleaseName, root.Namespace)
 		if err != nil {
-			chartVersion = fmt.Errorf("failed to get the deployed chart version: %v", err).Error()
+			res.DeployedChartError = fmt.Errorf("failed to get the deployed chart version: %v", err).Error()
 		} else {
-			chartVersion = fmt.Sprintf("deployed tobs helm chart version: %s", deployedChart.Version)
+			res.DeployedChartVersion = deployedChart.Version
 		}
 	} else {
 		err = helmClient.AddOrUpdateChartRepo(utils.DEFAULT_REGISTRY_NAME, utils.REPO_LOCATION)
@@ -50,9 +75,8 @@ func version(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			return fmt.Errorf("failed to get latest tobs helm chart version %v", err)
 		}
-		chartVersion = fmt.Sprintf("latest tobs helm chart version: %s", latestChart.Version)
+		res.LatestChartVersion = latestChart.Version
 	}
 
-	fmt.Printf("Tobs CLI Version: %s, %s \n", tobsVersion, chartVersion)
-	return nil
+	return common.PrintResult(res)
 }
@@ -2,6 +2,7 @@ package volume
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/spf13/cobra"
 	root "github.com/timescale/tobs/cli/cmd"
@@ -50,13 +51,14 @@ func volumeGet(cmd *cobra.Command, args []string) error {
 		promStorage, tsDBStorage, tsDBWal = true, true, true
 	}
 
+	res := &VolumeGetResult{}
 	k8sClient := k8s.NewClient()
 	if tsDBStorage {
 		results, err := k8sClient.GetPVCSizes(root.Namespace, pvcStorage, common.GetTimescaleDBLabels(root.HelmReleaseName))
 		if err != nil {
 			return fmt.Errorf("could not get timescaleDB-storage: %w", err)
 		}
-		volumeGetPrint(pvcStorage, results)
+		res.add(pvcStorage, results)
 	}
 
 	if tsDBWal {
@@ -64,7 +66,7 @@ func volumeGet(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			return fmt.Errorf("could not get timescaleDB-wal: %w", err)
 		}
-		volumeGetPrint(pvcWAL, results)
+		res.add(pvcWAL, results)
 	}
 
 	if promStorage {
@@ -76,37 +78,47 @@ func volumeGet(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			return fmt.Errorf("could not get prometheus-storage: %w", err)
 		}
-		volumeGetPrint(pvcPrometheusName, results)
+		res.add(pvcPrometheusName, results)
 	}
 
-	return nil
+	return common.PrintResult(res)
+}
+
+// VolumeGetResult is the PVC sizes grouped by PVC prefix
+type VolumeGetResult struct {
+	Volumes []VolumeResult `json:"volumes"`
+}
+
+type VolumeResult struct {
+	PVCPrefix string         `json:"pvcPrefix"`
+	PVCs      []*k8s.PVCData `json:"pvcs"`
 }
 
-func volumeGetPrint(pvcPrefix string, results []*k8s.PVCData) {
+func (v *VolumeGetResult) add(pvcPrefix string, results []*k8s.PVCData) {
 	if len(results) == 0 {
 		return
 	}
+	v.Volumes = append(v.Volumes, VolumeResult{PVCPrefix: pvcPrefix, PVCs: results})
+}
 
-	fmt.Printf("PVC's of %s\n", pvcPrefix)
-	for _, pvc := range results {
-		if pvc.SpecSize != pvc.StatusSize {
-			fmt.Printf("Existing size of PVC: %s is %s and PVC expansion is in progress to %s\n", pvc.Name, pvc.StatusSize, pvc.SpecSize)
-		} else {
-			fmt.Printf("Existing size of PVC: %s is %s\n", pvc.Name, pvc.SpecSize)
+func (v *VolumeGetResult) PrintText(w io.Writer) error {
+	for _, volume := range v.Volumes {
+		fmt.Fprintf(w, "PVC's of %s\n", volume.PVCPrefix)
+		for _, pvc := range volume.PVCs {
+			if pvc.SpecSize != pvc.StatusSize {
+				fmt.Fprintf(w, "Existing size of PVC: %s is %s and PVC expansion is in progress to %s\n", pvc.Name, pvc.StatusSize, pvc.SpecSize)
+			} else {
+				fmt.Fprintf(w, "Existing size of PVC: %s is %s\n", pvc.Name, pvc.SpecSize)
+			}
 		}
+		fmt.Fprintln(w)
 	}
-	fmt.Println()
+	return nil
 }
 
 func pvcPrometheus(release, namespace string) (string, error) {
 	helmClient := helm.NewClient(namespace)
 	defer helmClient.Close()
 
-	name, err := helmClient.ExportValuesFieldFromRelease(release, []string{"kube-prometheus-stack", "fullnameOverride"})
-	if err != nil {
-		return "", err
-	}
-
-	pvcName := "prometheus-" + fmt.Sprint(name) + "-prometheus-db"
-	return pvcName, nil
+	return common.GetPrometheusPVCName(helmClient, release)
 }
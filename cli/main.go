@@ -17,16 +17,26 @@ package main
 
 import (
 	"github.com/timescale/tobs/cli/cmd"
+	_ "github.com/timescale/tobs/cli/cmd/credentials"
+	_ "github.com/timescale/tobs/cli/cmd/debug"
 	_ "github.com/timescale/tobs/cli/cmd/grafana"
 	_ "github.com/timescale/tobs/cli/cmd/helm"
+	_ "github.com/timescale/tobs/cli/cmd/history"
 	_ "github.com/timescale/tobs/cli/cmd/install"
+	_ "github.com/timescale/tobs/cli/cmd/jaeger"
 	_ "github.com/timescale/tobs/cli/cmd/metrics"
 	_ "github.com/timescale/tobs/cli/cmd/port-forward"
 	_ "github.com/timescale/tobs/cli/cmd/prometheus"
 	_ "github.com/timescale/tobs/cli/cmd/promlens"
 	_ "github.com/timescale/tobs/cli/cmd/promscale"
+	_ "github.com/timescale/tobs/cli/cmd/rollback"
+	_ "github.com/timescale/tobs/cli/cmd/status"
 	_ "github.com/timescale/tobs/cli/cmd/timescaledb"
+	_ "github.com/timescale/tobs/cli/cmd/timescaledb/backup"
+	_ "github.com/timescale/tobs/cli/cmd/timescaledb/cluster"
 	_ "github.com/timescale/tobs/cli/cmd/timescaledb/superuser"
+	_ "github.com/timescale/tobs/cli/cmd/timescaledb/user"
+	_ "github.com/timescale/tobs/cli/cmd/traces"
 	_ "github.com/timescale/tobs/cli/cmd/uninstall"
 	_ "github.com/timescale/tobs/cli/cmd/upgrade"
 	_ "github.com/timescale/tobs/cli/cmd/version"
@@ -11,12 +11,17 @@ type Client interface {
 	InstallOrUpgradeChart(ctx context.Context, spec *ChartSpec) (*release.Release, error)
 	GetAllReleaseValues(name string) (map[string]interface{}, error)
 	GetReleaseValues(name string) (map[string]interface{}, error)
+	GetReleaseManifest(name string) (string, error)
 	GetChartValues(name string) ([]byte, error)
 	UninstallRelease(spec *ChartSpec) error
+	GetReleaseRevision(name string) (int, error)
+	Rollback(releaseName string, revision int) error
+	History(releaseName string) ([]ReleaseRevision, error)
 	GetDeployedChartMetadata(releaseName, namespace string) (*DeployedChartMetadata, error)
 	ExportValuesFieldFromRelease(releaseName string, keys []string) (interface{}, error)
 	ExportValuesFieldFromChart(chart string, customValuesFile string, keys []string) (interface{}, error)
 	GetChartMetadata(chart string) (*ChartMetadata, error)
+	GetChartCRDs(chart string) ([]string, error)
 	GetValuesYamlFromChart(chart, file string) (interface{}, error)
 	Close()
 }
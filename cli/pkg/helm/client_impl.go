@@ -7,6 +7,8 @@ import (
 	"io/ioutil"
 	"log"
 	"os"
+	"sort"
+	"time"
 
 	"github.com/timescale/tobs/cli/pkg/utils"
 	"helm.sh/helm/v3/pkg/action"
@@ -41,6 +43,7 @@ type clientImpl struct {
 	actionConfig      *action.Configuration
 	linting           bool
 	namespace         string
+	chartVersion      string
 	oldNamespaceValue string
 }
 
@@ -52,14 +55,23 @@ type ClientOptions struct {
 	Debug            bool
 	Linting          bool
 	DebugLog         action.DebugLog
+	// ChartVersion is the version of the charts the client looks up, the latest when empty
+	ChartVersion string
 }
 
 func NewClient(namespace string) Client {
+	return NewClientForChartVersion(namespace, "")
+}
+
+// NewClientForChartVersion returns a client that reads the metadata
+// and values of the provided version of the charts instead of the latest
+func NewClientForChartVersion(namespace, chartVersion string) Client {
 	opt := &ClientOptions{
 		Namespace:        namespace,
 		RepositoryConfig: defaultRepositoryConfigPath,
 		RepositoryCache:  defaultCachePath,
 		Linting:          true,
+		ChartVersion:     chartVersion,
 	}
 
 	// set helm namespace in env variable as
@@ -135,6 +147,7 @@ func newClient(options *ClientOptions, clientGetter genericclioptions.RESTClient
 		storage:      &repo.File{},
 		linting:      options.Linting,
 		namespace:    options.Namespace,
+		chartVersion: options.ChartVersion,
 	}, nil
 }
 
@@ -160,6 +173,33 @@ func (c *clientImpl) GetChartMetadata(chart string) (*ChartMetadata, error) {
 	return nil, fmt.Errorf("failed to get Chart.yaml from the provided chart")
 }
 
+// GetChartCRDs returns the names of the CRDs in the crds/ directories of the chart and its dependencies
+func (c *clientImpl) GetChartCRDs(chart string) ([]string, error) {
+	client := action.NewInstall(c.actionConfig)
+	helmChart, _, err := c.getChart(chart, &client.ChartPathOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, crd := range helmChart.CRDObjects() {
+		jsonCRD, err := yaml.ToJSON(crd.File.Data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse CRD %s: %w", crd.Filename, err)
+		}
+
+		var obj metaV1.PartialObjectMetadata
+		err = json.Unmarshal(jsonCRD, &obj)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse CRD %s: %w", crd.Filename, err)
+		}
+		if obj.Name != "" {
+			names = append(names, obj.Name)
+		}
+	}
+	return names, nil
+}
+
 func (c *clientImpl) GetDeployedChartMetadata(releaseName, namespace string) (*DeployedChartMetadata, error) {
 	var charts []DeployedChartMetadata
 	l, err := c.listDeployedReleases(namespace)
@@ -274,7 +314,7 @@ func (c *clientImpl) InstallOrUpgradeChart(ctx context.Context, spec *ChartSpec)
 	}
 
 	if installed {
-		return releaseInfo, c.upgrade(ctx, spec)
+		return c.upgrade(ctx, spec)
 	}
 
 	return c.install(spec)
@@ -293,6 +333,16 @@ func (c *clientImpl) GetReleaseValues(name string) (map[string]interface{}, erro
 	return getReleaseValuesClient.Run(name)
 }
 
+// GetReleaseManifest returns the rendered manifest of the deployed release.
+func (c *clientImpl) GetReleaseManifest(name string) (string, error) {
+	getClient := action.NewGet(c.actionConfig)
+	rel, err := getClient.Run(name)
+	if err != nil {
+		return "", err
+	}
+	return RenderedManifest(rel), nil
+}
+
 // GetChartValues returns the values from chart.
 func (c *clientImpl) GetChartValues(name string) ([]byte, error) {
 	client := action.NewInstall(c.actionConfig)
@@ -323,6 +373,55 @@ func (c *clientImpl) UninstallRelease(spec *ChartSpec) error {
 	return nil
 }
 
+// GetReleaseRevision returns the revision of the release
+func (c *clientImpl) GetReleaseRevision(name string) (int, error) {
+	getClient := action.NewGet(c.actionConfig)
+	rel, err := getClient.Run(name)
+	if err != nil {
+		return 0, err
+	}
+	return rel.Version, nil
+}
+
+// Rollback rolls the release back to the revision, 0 rolls back to the previous revision.
+// The manifest of the revision is applied as a new revision, recreating the deleted resources.
+func (c *clientImpl) Rollback(releaseName string, revision int) error {
+	client := action.NewRollback(c.actionConfig)
+	client.Version = revision
+	client.Timeout = 5 * time.Minute
+	client.CleanupOnFail = true
+	return client.Run(releaseName)
+}
+
+// History returns the revisions of the release sorted from the oldest to the newest.
+func (c *clientImpl) History(releaseName string) ([]ReleaseRevision, error) {
+	client := action.NewHistory(c.actionConfig)
+	releases, err := client.Run(releaseName)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := make([]ReleaseRevision, 0, len(releases))
+	for _, r := range releases {
+		rev := ReleaseRevision{
+			Revision: r.Version,
+			Values:   r.Config,
+		}
+		if r.Info != nil {
+			rev.Updated = r.Info.LastDeployed.String()
+			rev.Status = r.Info.Status.String()
+			rev.Description = r.Info.Description
+		}
+		if r.Chart != nil && r.Chart.Metadata != nil {
+			rev.ChartVersion = r.Chart.Metadata.Version
+			rev.AppVersion = r.Chart.Metadata.AppVersion
+		}
+		revisions = append(revisions, rev)
+	}
+	sort.Slice(revisions, func(i, j int) bool { return revisions[i].Revision < revisions[j].Revision })
+	return revisions, nil
+}
+
 // install lints and installs the provided chart
 func (c *clientImpl) install(spec *ChartSpec) (*release.Release, error) {
 	release := &release.Release{}
@@ -358,48 +457,58 @@ func (c *clientImpl) install(spec *ChartSpec) (*release.Release, error) {
 		return release, err
 	}
 
+	if spec.DryRun {
+		return release, nil
+	}
+
 	log.Printf("release installed successfully: %s/%s-%s", release.Name, release.Name, release.Chart.Metadata.Version)
 
 	return release, nil
 }
 
 // upgrade upgrades a chart and CRDs
-func (c *clientImpl) upgrade(ctx context.Context, spec *ChartSpec) error {
+func (c *clientImpl) upgrade(ctx context.Context, spec *ChartSpec) (*release.Release, error) {
+	rel := &release.Release{}
 	client := action.NewUpgrade(c.actionConfig)
 	setUpgradeOptions(spec, client)
 	helmChart, chartPath, err := c.getChart(spec.ChartName, &client.ChartPathOptions)
 	if err != nil {
-		return err
+		return rel, err
 	}
 
 	values, err := spec.GetValuesMap()
 	if err != nil {
-		return err
+		return rel, err
 	}
 
 	if c.linting {
 		err = c.lint(chartPath, values)
 		if err != nil {
-			return err
+			return rel, err
 		}
 	}
 
-	if !spec.SkipCRDs && spec.UpgradeCRDs {
+	// on dry-run CRDs must not be touched
+	if !spec.SkipCRDs && spec.UpgradeCRDs && !spec.DryRun {
 		log.Printf("updating crds")
 		err = c.upgradeCRDs(ctx, helmChart)
 		if err != nil {
-			return err
+			return rel, err
 		}
 	}
 
-	rel, err := client.Run(spec.ReleaseName, helmChart, values)
+	rel, err = client.Run(spec.ReleaseName, helmChart, values)
 	if err != nil {
-		return err
+		return rel, err
+	}
+
+	if spec.DryRun {
+		return rel, nil
 	}
 
 	log.Printf("release upgrade successfully: %s/%s-%s", rel.Name, rel.Name, rel.Chart.Metadata.Version)
 
-	return nil
+	return rel, nil
 }
 
 // lint lints a chart's values
@@ -506,6 +615,9 @@ func (c *clientImpl) upgradeCRDs(ctx context.Context, chartInstance *chart.Chart
 
 // getChart returns a chart matching the provided chart name and options
 func (c *clientImpl) getChart(chartName string, chartPathOptions *action.ChartPathOptions) (*chart.Chart, string, error) {
+	if chartPathOptions.Version == "" {
+		chartPathOptions.Version = c.chartVersion
+	}
 	chartPath, err := chartPathOptions.LocateChart(chartName, c.settings)
 	if err != nil {
 		return nil, "", err
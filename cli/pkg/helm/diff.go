@@ -0,0 +1,236 @@
+package helm
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	ResourceAdded     = "added"
+	ResourceRemoved   = "removed"
+	ResourceModified  = "modified"
+	ResourceUnchanged = "unchanged"
+)
+
+// ResourceDiff is the difference of a single kubernetes resource between two manifests
+type ResourceDiff struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Action    string
+	Diff      string
+}
+
+// ID returns the identifier of the resource in the form of kind/namespace/name
+func (r ResourceDiff) ID() string {
+	if r.Namespace == "" {
+		return fmt.Sprintf("%s/%s", r.Kind, r.Name)
+	}
+	return fmt.Sprintf("%s/%s/%s", r.Kind, r.Namespace, r.Name)
+}
+
+type manifestHead struct {
+	Kind     string `json:"kind"`
+	Metadata struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"metadata"`
+}
+
+type manifestResource struct {
+	kind      string
+	namespace string
+	name      string
+	body      string
+}
+
+// RenderedManifest returns all the manifests of a release
+// including the hooks in the same format as helm template.
+func RenderedManifest(rel *release.Release) string {
+	var b strings.Builder
+	for _, hook := range rel.Hooks {
+		fmt.Fprintf(&b, "---\n# Source: %s\n%s\n", hook.Path, strings.TrimSpace(hook.Manifest))
+	}
+	b.WriteString(rel.Manifest)
+	return b.String()
+}
+
+// DiffManifests compares two multi-document manifests resource by resource
+// and returns the difference sorted by resource identifier.
+func DiffManifests(oldManifest, newManifest string) ([]ResourceDiff, error) {
+	oldResources, err := parseManifest(oldManifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse existing manifest %w", err)
+	}
+	newResources, err := parseManifest(newManifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse new manifest %w", err)
+	}
+
+	var diffs []ResourceDiff
+	for id, n := range newResources {
+		d := ResourceDiff{Kind: n.kind, Namespace: n.namespace, Name: n.name}
+		o, exists := oldResources[id]
+		switch {
+		case !exists:
+			d.Action = ResourceAdded
+			d.Diff = diffText("", n.body)
+		case o.body != n.body:
+			d.Action = ResourceModified
+			d.Diff = diffText(o.body, n.body)
+		default:
+			d.Action = ResourceUnchanged
+		}
+		diffs = append(diffs, d)
+	}
+
+	for id, o := range oldResources {
+		if _, exists := newResources[id]; !exists {
+			diffs = append(diffs, ResourceDiff{
+				Kind:      o.kind,
+				Namespace: o.namespace,
+				Name:      o.name,
+				Action:    ResourceRemoved,
+				Diff:      diffText(o.body, ""),
+			})
+		}
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].ID() < diffs[j].ID()
+	})
+	return diffs, nil
+}
+
+func parseManifest(manifest string) (map[string]manifestResource, error) {
+	resources := make(map[string]manifestResource)
+	for _, doc := range releaseutil.SplitManifests(manifest) {
+		var head manifestHead
+		err := yaml.Unmarshal([]byte(doc), &head)
+		if err != nil {
+			return nil, err
+		}
+		// skip documents which are only comments
+		if head.Kind == "" {
+			continue
+		}
+
+		r := manifestResource{
+			kind:      head.Kind,
+			namespace: head.Metadata.Namespace,
+			name:      head.Metadata.Name,
+			body:      stripSourceComment(doc),
+		}
+		resources[r.kind+"/"+r.namespace+"/"+r.name] = r
+	}
+	return resources, nil
+}
+
+// helm prefixes each document with the template path
+// this isn't part of the resource so drop it before comparing
+func stripSourceComment(doc string) string {
+	lines := strings.Split(doc, "\n")
+	if len(lines) > 0 && strings.HasPrefix(lines[0], "# Source:") {
+		lines = lines[1:]
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+func diffText(oldText, newText string) string {
+	// encode each distinct line as a single rune so the diff
+	// is computed line by line instead of character by character
+	lines := make(map[rune]string)
+	index := make(map[string]rune)
+	encode := func(text string) string {
+		if text == "" {
+			return ""
+		}
+		var b strings.Builder
+		for _, line := range strings.Split(text, "\n") {
+			r, ok := index[line]
+			if !ok {
+				r = rune(len(index) + 1)
+				// surrogate halves are not valid runes
+				if r >= 0xD800 {
+					r += 0x800
+				}
+				index[line] = r
+				lines[r] = line
+			}
+			b.WriteRune(r)
+		}
+		return b.String()
+	}
+	a := encode(oldText)
+	b := encode(newText)
+
+	dmp := diffmatchpatch.New()
+	var out strings.Builder
+	for _, d := range dmp.DiffMain(a, b, false) {
+		var prefix string
+		switch d.Type {
+		case diffmatchpatch.DiffInsert:
+			prefix = "+ "
+		case diffmatchpatch.DiffDelete:
+			prefix = "- "
+		default:
+			prefix = "  "
+		}
+		for _, r := range d.Text {
+			out.WriteString(prefix + lines[r] + "\n")
+		}
+	}
+	return out.String()
+}
+
+// ValueDiff is the change of a single value between two sets of helm values
+type ValueDiff struct {
+	// Path is the dotted path of the value
+	Path   string
+	Action string
+	// Old and New are the values before and after the change,
+	// nil when the value was added or removed
+	Old interface{}
+	New interface{}
+}
+
+// DiffValues compares two sets of helm values and returns the changed paths sorted.
+// Maps are compared key by key, any other value, including lists, as a whole.
+func DiffValues(oldValues, newValues map[string]interface{}) []ValueDiff {
+	var diffs []ValueDiff
+	diffValues("", oldValues, newValues, &diffs)
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Path < diffs[j].Path })
+	return diffs
+}
+
+func diffValues(prefix string, oldValues, newValues map[string]interface{}, diffs *[]ValueDiff) {
+	for k, oldValue := range oldValues {
+		path := prefix + k
+		newValue, ok := newValues[k]
+		if !ok {
+			*diffs = append(*diffs, ValueDiff{Path: path, Action: ResourceRemoved, Old: oldValue})
+			continue
+		}
+		oldMap, oldIsMap := oldValue.(map[string]interface{})
+		newMap, newIsMap := newValue.(map[string]interface{})
+		if oldIsMap && newIsMap {
+			diffValues(path+".", oldMap, newMap, diffs)
+			continue
+		}
+		if !reflect.DeepEqual(oldValue, newValue) {
+			*diffs = append(*diffs, ValueDiff{Path: path, Action: ResourceModified, Old: oldValue, New: newValue})
+		}
+	}
+	for k, newValue := range newValues {
+		if _, ok := oldValues[k]; !ok {
+			*diffs = append(*diffs, ValueDiff{Path: prefix + k, Action: ResourceAdded, New: newValue})
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package helm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffManifests(t *testing.T) {
+	oldManifest := `---
+# Source: tobs/templates/svc.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: tobs-promscale
+  namespace: ns
+spec:
+  port: 9201
+---
+# Source: tobs/templates/cm.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: tobs-config
+  namespace: ns
+data:
+  key: value
+---
+# Source: tobs/templates/job.yaml
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: tobs-grafana-db
+  namespace: ns`
+
+	newManifest := `---
+# Source: tobs/templates/svc.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: tobs-promscale
+  namespace: ns
+spec:
+  port: 9202
+---
+# Source: tobs/templates/cm-renamed.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: tobs-config
+  namespace: ns
+data:
+  key: value
+---
+# Source: tobs/templates/crb.yaml
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: tobs-binding`
+
+	got, err := DiffManifests(oldManifest, newManifest)
+	if err != nil {
+		t.Fatalf("DiffManifests() error = %v", err)
+	}
+
+	wantActions := map[string]string{
+		"ClusterRoleBinding/tobs-binding": ResourceAdded,
+		"ConfigMap/ns/tobs-config":        ResourceUnchanged,
+		"Job/ns/tobs-grafana-db":          ResourceRemoved,
+		"Service/ns/tobs-promscale":       ResourceModified,
+	}
+	gotActions := make(map[string]string)
+	var ids []string
+	for _, d := range got {
+		gotActions[d.ID()] = d.Action
+		ids = append(ids, d.ID())
+	}
+	if !reflect.DeepEqual(gotActions, wantActions) {
+		t.Errorf("DiffManifests() actions got = %v, want %v", gotActions, wantActions)
+	}
+
+	wantOrder := []string{"ClusterRoleBinding/tobs-binding", "ConfigMap/ns/tobs-config", "Job/ns/tobs-grafana-db", "Service/ns/tobs-promscale"}
+	if !reflect.DeepEqual(ids, wantOrder) {
+		t.Errorf("DiffManifests() order got = %v, want %v", ids, wantOrder)
+	}
+
+	for _, d := range got {
+		if d.ID() != "Service/ns/tobs-promscale" {
+			continue
+		}
+		want := `  apiVersion: v1
+  kind: Service
+  metadata:
+    name: tobs-promscale
+    namespace: ns
+  spec:
+-   port: 9201
++   port: 9202
+`
+		if d.Diff != want {
+			t.Errorf("DiffManifests() diff got =\n%v\nwant =\n%v", d.Diff, want)
+		}
+	}
+}
+
+func TestDiffManifestsEmpty(t *testing.T) {
+	got, err := DiffManifests("", "")
+	if err != nil {
+		t.Fatalf("DiffManifests() error = %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("DiffManifests() got = %v, want no diffs", got)
+	}
+}
+
+func TestDiffValues(t *testing.T) {
+	oldValues := map[string]interface{}{
+		"timescaledbExternal": map[string]interface{}{"enabled": false},
+		"promscale": map[string]interface{}{
+			"tracing": map[string]interface{}{"enabled": true},
+			"args":    []interface{}{"--high-availability"},
+			"connection": map[string]interface{}{
+				"password": "a",
+				"port":     5432,
+			},
+		},
+	}
+	newValues := map[string]interface{}{
+		"promscale": map[string]interface{}{
+			"openTelemetry": map[string]interface{}{"enabled": true},
+			"args":          []interface{}{"--metrics.high-availability"},
+			"connection": map[string]interface{}{
+				"password": "b",
+				"port":     5432,
+			},
+		},
+	}
+
+	want := []ValueDiff{
+		{Path: "promscale.args", Action: ResourceModified, Old: []interface{}{"--high-availability"}, New: []interface{}{"--metrics.high-availability"}},
+		{Path: "promscale.connection.password", Action: ResourceModified, Old: "a", New: "b"},
+		{Path: "promscale.openTelemetry", Action: ResourceAdded, New: map[string]interface{}{"enabled": true}},
+		{Path: "promscale.tracing", Action: ResourceRemoved, Old: map[string]interface{}{"enabled": true}},
+		{Path: "timescaledbExternal", Action: ResourceRemoved, Old: map[string]interface{}{"enabled": false}},
+	}
+	got := DiffValues(oldValues, newValues)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffValues() got = %v, want %v", got, want)
+	}
+
+	if got := DiffValues(oldValues, oldValues); len(got) != 0 {
+		t.Errorf("DiffValues() got = %v, want no diffs", got)
+	}
+}
@@ -14,6 +14,7 @@ type ChartMetadata struct {
 		Version    string `yaml:"version"`
 	} `yaml:"dependencies"`
 	Description string `yaml:"description"`
+	KubeVersion string `yaml:"kubeVersion"`
 	Name        string `yaml:"name"`
 	Type        string `yaml:"type"`
 	Version     string `yaml:"version"`
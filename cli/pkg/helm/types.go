@@ -72,3 +72,15 @@ type ChartSpec struct {
 	// +optional
 	DryRun bool `json:"dryRun,omitempty"`
 }
+
+// ReleaseRevision is a single revision of the release history
+type ReleaseRevision struct {
+	Revision     int    `json:"revision"`
+	Updated      string `json:"updated"`
+	Status       string `json:"status"`
+	ChartVersion string `json:"chartVersion"`
+	AppVersion   string `json:"appVersion"`
+	Description  string `json:"description"`
+	// Values are the user-supplied values of the revision
+	Values map[string]interface{} `json:"-"`
+}
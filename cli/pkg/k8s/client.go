@@ -5,7 +5,6 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/client-go/tools/portforward"
 )
 
 type Client interface {
@@ -17,18 +16,21 @@ type Client interface {
 	KubeGetPods(namespace string, labelmap map[string]string) ([]corev1.Pod, error)
 	KubeGetAllPods(namespace string, name string) ([]corev1.Pod, error)
 	DeletePods(namespace string, labels map[string]string, forceKill bool) error
-	KubePortForwardPod(namespace string, podName string, local int, remote int) (*portforward.PortForwarder, error)
+	KubePortForwardPod(namespace string, podName string, local int, remote int) (*PortForward, error)
+	KubeGetPodLogs(namespace string, podName string, opts *corev1.PodLogOptions) ([]byte, error)
+	KubeGetPodEvents(namespace string, podName string) ([]corev1.Event, error)
 
 	// deployment specific actions
 	GetDeployment(name, namespace string) (*appsv1.Deployment, error)
 	UpdateDeployment(deployment *appsv1.Deployment) error
 	DeleteDeployment(labels map[string]string, namespace string) error
+	RolloutRestartDeployments(namespace string, labels map[string]string) ([]string, error)
 
 	// service specific actions
 	KubeGetServiceName(namespace string, labelmap map[string]string) (string, error)
 	KubeDeleteService(namespace string, serviceName string) error
 	KubeDeleteEndpoint(namespace string, endpointName string) error
-	KubePortForwardService(namespace string, serviceName string, local int, remote int) (*portforward.PortForwarder, error)
+	KubePortForwardService(namespace string, serviceName string, local int, remote int) (*PortForward, error)
 
 	// job specific actions
 	CreateJob(job *batchv1.Job) error
@@ -44,6 +46,7 @@ type Client interface {
 	GetPVCSizes(namespace, pvcPrefix string, labels map[string]string) ([]*PVCData, error)
 	ExpandPVCsForAllPods(namespace, value, pvcPrefix string, labels map[string]string) (map[string]string, error)
 	ExpandPVC(namespace, pvcName, value string) error
+	GetPVCVolumeStats(namespace string) ([]*VolumeStats, error)
 
 	// pv specific actions
 	UpdatePVToNewPVC(pvcName, newPVCName, namespace string, pvcLabels map[string]string) error
@@ -58,6 +61,10 @@ type Client interface {
 
 	// exec into the container
 	KubeExecCmd(namespace string, podName string, container string, command string, stdin io.Reader, tty bool) error
+	KubeExecCmdWithStreams(namespace string, podName string, container string, command string, stdin io.Reader, stdout, stderr io.Writer) error
+
+	// cluster specific actions
+	GetServerVersion() (string, error)
 
 	// namespace specific actions
 	CreateNamespaceIfNotExists(namespace string) error
@@ -26,10 +26,12 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/serializer/yaml"
 	"k8s.io/apimachinery/pkg/types"
 	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/discovery/cached/disk"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
@@ -247,8 +249,32 @@ func (c *clientImpl) KubeGetAllPods(namespace string, releaseName string) ([]cor
 	return allpods, nil
 }
 
+func (c *clientImpl) KubeGetPodLogs(namespace string, podName string, opts *corev1.PodLogOptions) ([]byte, error) {
+	return c.CoreV1().Pods(namespace).GetLogs(podName, opts).DoRaw(context.Background())
+}
+
+func (c *clientImpl) KubeGetPodEvents(namespace string, podName string) ([]corev1.Event, error) {
+	events, err := c.CoreV1().Events(namespace).List(context.Background(), metav1.ListOptions{
+		FieldSelector: "involvedObject.kind=Pod,involvedObject.name=" + podName,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return events.Items, nil
+}
+
 // ExecCmd exec command on specific pod and wait the command's output.
 func (c *clientImpl) KubeExecCmd(namespace string, podName string, container string, command string, stdin io.Reader, tty bool) error {
+	return c.execCmd(namespace, podName, container, command, stdin, os.Stdout, os.Stdout, tty)
+}
+
+// KubeExecCmdWithStreams executes the command without a tty, writing its output to stdout and stderr
+func (c *clientImpl) KubeExecCmdWithStreams(namespace string, podName string, container string, command string, stdin io.Reader, stdout, stderr io.Writer) error {
+	return c.execCmd(namespace, podName, container, command, stdin, stdout, stderr, false)
+}
+
+func (c *clientImpl) execCmd(namespace string, podName string, container string, command string, stdin io.Reader, stdout, stderr io.Writer, tty bool) error {
 	var err error
 
 	shcmd := []string{
@@ -281,8 +307,8 @@ func (c *clientImpl) KubeExecCmd(namespace string, podName string, container str
 
 	err = exec.Stream(remotecommand.StreamOptions{
 		Stdin:  stdin,
-		Stdout: os.Stdout,
-		Stderr: os.Stdout,
+		Stdout: stdout,
+		Stderr: stderr,
 	})
 	if err != nil {
 		return err
@@ -291,10 +317,14 @@ func (c *clientImpl) KubeExecCmd(namespace string, podName string, container str
 	return nil
 }
 
-func (c *clientImpl) KubePortForwardPod(namespace string, podName string, local int, remote int) (*portforward.PortForwarder, error) {
+func (c *clientImpl) KubePortForwardPod(namespace string, podName string, local int, remote int) (*PortForward, error) {
 	var err error
 
-	fmt.Printf("Listening to pod %v from port %d\n", podName, local)
+	pod, err := c.CoreV1().Pods(namespace).Get(context.Background(), podName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
 	url := c.CoreV1().RESTClient().Post().
 		Resource("pods").
 		Namespace(namespace).
@@ -310,25 +340,45 @@ func (c *clientImpl) KubePortForwardPod(namespace string, podName string, local
 
 	ports := []string{fmt.Sprintf("%d:%d", local, remote)}
 
-	pf, err := portforward.New(dialer, ports, make(chan struct{}, 1), make(chan struct{}, 1), os.Stdout, os.Stderr)
+	stopChan := make(chan struct{})
+	pf, err := portforward.New(dialer, ports, stopChan, make(chan struct{}, 1), os.Stdout, os.Stderr)
 	if err != nil {
 		return nil, err
 	}
 
-	errChan := make(chan error)
+	p := &PortForward{PortForwarder: pf, stopChan: stopChan, done: make(chan struct{})}
 	go func() {
-		errChan <- pf.ForwardPorts()
+		p.finish(pf.ForwardPorts())
 	}()
+	// the first watch starts at the version of the pod forwarded to, the next
+	// ones get the current pod first so the changes in between are noticed
+	resourceVersion := pod.ResourceVersion
+	go p.monitorPod(func() (watch.Interface, error) {
+		w, err := c.CoreV1().Pods(namespace).Watch(context.Background(), metav1.ListOptions{
+			FieldSelector:   fields.OneTermEqualSelector("metadata.name", podName).String(),
+			ResourceVersion: resourceVersion,
+		})
+		resourceVersion = ""
+		return w, err
+	}, pod)
 
 	select {
-	case err = <-errChan:
-		return nil, err
+	case <-p.done:
+		return nil, p.err
 	case <-pf.Ready:
-		return pf, nil
 	}
+
+	forwarded, err := pf.GetPorts()
+	if err != nil {
+		p.Stop()
+		return nil, err
+	}
+	p.localPort = int(forwarded[0].Local)
+	fmt.Printf("Listening to pod %v from port %d\n", podName, p.localPort)
+	return p, nil
 }
 
-func (c *clientImpl) KubePortForwardService(namespace string, serviceName string, local int, remote int) (*portforward.PortForwarder, error) {
+func (c *clientImpl) KubePortForwardService(namespace string, serviceName string, local int, remote int) (*PortForward, error) {
 	var err error
 
 	service, err := c.CoreV1().Services(namespace).Get(context.Background(), serviceName, metav1.GetOptions{})
@@ -346,7 +396,11 @@ func (c *clientImpl) KubePortForwardService(namespace string, serviceName string
 	if len(pods.Items) == 0 {
 		return nil, fmt.Errorf("couldn't find the pods for service: %s", serviceName)
 	}
-	podName := pods.Items[0].Name
+	pod, ok := runningPod(pods.Items)
+	if !ok {
+		return nil, fmt.Errorf("couldn't find a running pod for service: %s", serviceName)
+	}
+	podName := pod.Name
 
 	pf, err := c.KubePortForwardPod(namespace, podName, local, remote)
 	if err != nil {
@@ -456,9 +510,9 @@ func buildPVCNames(pvcPrefix string, pods []corev1.Pod) (pvcNames []string) {
 }
 
 type PVCData struct {
-	Name       string
-	SpecSize   string
-	StatusSize string
+	Name       string `json:"name"`
+	SpecSize   string `json:"specSize"`
+	StatusSize string `json:"statusSize"`
 }
 
 func (c *clientImpl) GetPVCSizes(namespace, pvcPrefix string, labels map[string]string) ([]*PVCData, error) {
@@ -695,6 +749,33 @@ func (c *clientImpl) UpdateDeployment(deployment *appsv1.Deployment) error {
 	return err
 }
 
+// RolloutRestartDeployments restarts the deployments matching the labels the way kubectl
+// rollout restart does, and returns the names of the restarted deployments
+func (c *clientImpl) RolloutRestartDeployments(namespace string, labelmap map[string]string) ([]string, error) {
+	set := labels.Set(labelmap)
+	listOptions := metav1.ListOptions{LabelSelector: set.AsSelector().String()}
+	deployments, err := c.AppsV1().Deployments(namespace).List(context.Background(), listOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, d := range deployments.Items {
+		d := d
+		if d.Spec.Template.Annotations == nil {
+			d.Spec.Template.Annotations = make(map[string]string)
+		}
+		d.Spec.Template.Annotations["kubectl.kubernetes.io/restartedAt"] = time.Now().Format(time.RFC3339)
+		_, err = c.AppsV1().Deployments(namespace).Update(context.Background(), &d, metav1.UpdateOptions{})
+		if err != nil {
+			return names, fmt.Errorf("failed to restart deployment %s: %w", d.Name, err)
+		}
+		names = append(names, d.Name)
+	}
+
+	return names, nil
+}
+
 func (c *clientImpl) DeleteDeployment(labelmap map[string]string, namespace string) error {
 	labelSelector := metav1.LabelSelector{MatchLabels: labelmap}
 	listOptions := metav1.ListOptions{
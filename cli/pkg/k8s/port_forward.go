@@ -0,0 +1,282 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/portforward"
+)
+
+// PortForward is a running port-forward to a pod
+type PortForward struct {
+	*portforward.PortForwarder
+	stopChan chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+	err      error
+	// lost is the reason the port-forward was torn down for, set by fail
+	// from the pod monitor while finish reads it, so it's guarded by mu
+	mu   sync.Mutex
+	lost error
+	// localPort is the port listened from, which is
+	// picked by the OS when forwarding from port 0
+	localPort int
+}
+
+// LocalPort returns the local port the port-forward listens from
+func (p *PortForward) LocalPort() int {
+	return p.localPort
+}
+
+// Done is closed when the port-forward stops, either because
+// the connection to the pod was lost or Stop was called.
+func (p *PortForward) Done() <-chan struct{} {
+	return p.done
+}
+
+// Err returns the error the port-forward stopped with
+func (p *PortForward) Err() error {
+	<-p.done
+	return p.err
+}
+
+// Stop closes the port-forward and waits for it to release the local port
+func (p *PortForward) Stop() {
+	p.stopOnce.Do(func() {
+		close(p.stopChan)
+	})
+	<-p.done
+}
+
+// fail tears the port-forward down with err, unless it's already stopped
+func (p *PortForward) fail(err error) {
+	p.stopOnce.Do(func() {
+		p.mu.Lock()
+		p.lost = err
+		p.mu.Unlock()
+		close(p.stopChan)
+	})
+}
+
+// finish marks the port-forward as stopped with err, or with the reason it was torn down for
+func (p *PortForward) finish(err error) {
+	p.mu.Lock()
+	if p.lost != nil {
+		err = p.lost
+	}
+	p.mu.Unlock()
+	p.err = err
+	close(p.done)
+}
+
+// runningPod returns the first of the pods which is running and not being deleted,
+// so a port-forward doesn't connect to a pod a rollout is terminating
+func runningPod(pods []corev1.Pod) (*corev1.Pod, bool) {
+	for i := range pods {
+		if pods[i].Status.Phase == corev1.PodRunning && pods[i].DeletionTimestamp == nil {
+			return &pods[i], true
+		}
+	}
+	return nil, false
+}
+
+// monitorPod tears the port-forward down when the pod it forwards to is deleted or restarts.
+// The SPDY connection of the port-forward outlives the pod, so without the monitor the
+// port-forward stays up after a pod restart and only fails the new connections.
+func (p *PortForward) monitorPod(watchPod func() (watch.Interface, error), pod *corev1.Pod) {
+	for {
+		w, err := watchPod()
+		if err == nil {
+			lost, ok := podLost(w.ResultChan(), p.done, pod)
+			w.Stop()
+			if lost != nil {
+				p.fail(lost)
+				return
+			}
+			if !ok {
+				return
+			}
+		}
+
+		// the watch ended or the API server is briefly unavailable,
+		// watch again until the port-forward stops
+		select {
+		case <-p.done:
+			return
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// podLost reads the watch events of the pod until it's deleted, replaced or restarted and returns why.
+// It returns ok false when done is closed and a nil error with ok true when the watch ended or failed.
+func podLost(events <-chan watch.Event, done <-chan struct{}, pod *corev1.Pod) (lost error, ok bool) {
+	restarts := podRestarts(pod)
+	for {
+		select {
+		case <-done:
+			return nil, false
+		case e, open := <-events:
+			if !open {
+				return nil, true
+			}
+			switch e.Type {
+			case watch.Error:
+				return nil, true
+			case watch.Deleted:
+				return fmt.Errorf("pod %s was deleted", pod.Name), true
+			case watch.Added, watch.Modified:
+				current, isPod := e.Object.(*corev1.Pod)
+				if !isPod {
+					continue
+				}
+				if current.UID != pod.UID {
+					return fmt.Errorf("pod %s was replaced", pod.Name), true
+				}
+				if current.DeletionTimestamp != nil {
+					return fmt.Errorf("pod %s is terminating", pod.Name), true
+				}
+				if podRestarts(current) > restarts {
+					return fmt.Errorf("pod %s restarted", pod.Name), true
+				}
+				if current.Status.Phase != corev1.PodRunning {
+					return fmt.Errorf("pod %s is %s", pod.Name, current.Status.Phase), true
+				}
+			}
+		}
+	}
+}
+
+func podRestarts(pod *corev1.Pod) int32 {
+	var restarts int32
+	for _, c := range pod.Status.ContainerStatuses {
+		restarts += c.RestartCount
+	}
+	return restarts
+}
+
+// ForwardFunc starts a port-forward. It's called again on every reconnect
+// so it should resolve the pod or service each time as the pod can change.
+type ForwardFunc func() (*PortForward, error)
+
+type supervisedForward struct {
+	name    string
+	forward ForwardFunc
+}
+
+// PortForwardSupervisor keeps port-forwards running by
+// reconnecting them with backoff when the connection is lost.
+type PortForwardSupervisor struct {
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Out            io.Writer
+	// OnReady is called once all the port-forwards are started
+	OnReady func()
+
+	forwards []supervisedForward
+}
+
+func NewPortForwardSupervisor() *PortForwardSupervisor {
+	return &PortForwardSupervisor{
+		InitialBackoff: time.Second,
+		MaxBackoff:     30 * time.Second,
+		Out:            os.Stdout,
+	}
+}
+
+// Add registers a port-forward, name is used to log its state changes
+func (s *PortForwardSupervisor) Add(name string, forward ForwardFunc) {
+	s.forwards = append(s.forwards, supervisedForward{name: name, forward: forward})
+}
+
+// Run starts all the port-forwards and keeps them running until the context is
+// cancelled. It fails without retrying if any port-forward can't be started.
+func (s *PortForwardSupervisor) Run(ctx context.Context) error {
+	started := make([]*PortForward, 0, len(s.forwards))
+	for _, f := range s.forwards {
+		pf, err := f.forward()
+		if err != nil {
+			for _, p := range started {
+				p.Stop()
+			}
+			return err
+		}
+		started = append(started, pf)
+	}
+
+	if s.OnReady != nil {
+		s.OnReady()
+	}
+
+	var wg sync.WaitGroup
+	for i, f := range s.forwards {
+		wg.Add(1)
+		go func(f supervisedForward, pf *PortForward) {
+			defer wg.Done()
+			s.supervise(ctx, f, pf)
+		}(f, started[i])
+	}
+
+	wg.Wait()
+	return nil
+}
+
+func (s *PortForwardSupervisor) supervise(ctx context.Context, f supervisedForward, pf *PortForward) {
+	for {
+		select {
+		case <-ctx.Done():
+			pf.Stop()
+			s.logf("%s: port-forward stopped", f.name)
+			return
+		case <-pf.Done():
+		}
+
+		if err := pf.Err(); err != nil {
+			s.logf("%s: lost connection: %v", f.name, err)
+		} else {
+			s.logf("%s: lost connection", f.name)
+		}
+
+		pf = s.reconnect(ctx, f)
+		if pf == nil {
+			s.logf("%s: port-forward stopped", f.name)
+			return
+		}
+		s.logf("%s: reconnected", f.name)
+	}
+}
+
+// reconnect retries the port-forward with exponential backoff
+// until it succeeds or returns nil when the context is cancelled
+func (s *PortForwardSupervisor) reconnect(ctx context.Context, f supervisedForward) *PortForward {
+	backoff := s.InitialBackoff
+	for {
+		s.logf("%s: reconnecting in %v", f.name, backoff)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+
+		pf, err := f.forward()
+		if err == nil {
+			return pf
+		}
+		s.logf("%s: failed to reconnect: %v", f.name, err)
+
+		backoff *= 2
+		if backoff > s.MaxBackoff {
+			backoff = s.MaxBackoff
+		}
+	}
+}
+
+func (s *PortForwardSupervisor) logf(format string, args ...interface{}) {
+	fmt.Fprintf(s.Out, time.Now().Format("15:04:05")+" "+format+"\n", args...)
+}
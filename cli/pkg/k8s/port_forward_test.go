@@ -0,0 +1,242 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// fakePortForward returns a port-forward which
+// stops when Stop is called or kill is closed
+func fakePortForward(kill chan struct{}) *PortForward {
+	p := &PortForward{stopChan: make(chan struct{}), done: make(chan struct{})}
+	go func() {
+		select {
+		case <-p.stopChan:
+			p.finish(nil)
+		case <-kill:
+			p.finish(errors.New("lost connection to pod"))
+		}
+	}()
+	return p
+}
+
+func testPod(uid types.UID, restarts int32) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "tobs-grafana-0", UID: uid},
+		Status: corev1.PodStatus{
+			Phase:             corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{{Name: "grafana", RestartCount: restarts}},
+		},
+	}
+}
+
+func TestPortForwardSupervisor_Reconnect(t *testing.T) {
+	kill := make(chan struct{})
+	reconnected := make(chan *PortForward, 1)
+	calls := 0
+	forward := func() (*PortForward, error) {
+		calls++
+		switch calls {
+		case 1:
+			return fakePortForward(kill), nil
+		case 2:
+			return nil, errors.New("pod not ready")
+		default:
+			p := fakePortForward(make(chan struct{}))
+			reconnected <- p
+			return p, nil
+		}
+	}
+
+	var out bytes.Buffer
+	s := NewPortForwardSupervisor()
+	s.InitialBackoff = time.Millisecond
+	s.MaxBackoff = 2 * time.Millisecond
+	s.Out = &out
+	ready := false
+	s.OnReady = func() { ready = true }
+	s.Add("Grafana", forward)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errChan := make(chan error)
+	go func() {
+		errChan <- s.Run(ctx)
+	}()
+
+	close(kill)
+	var p *PortForward
+	select {
+	case p = <-reconnected:
+	case <-time.After(5 * time.Second):
+		t.Fatal("PortForwardSupervisor.Run() didn't reconnect")
+	}
+
+	cancel()
+	if err := <-errChan; err != nil {
+		t.Errorf("PortForwardSupervisor.Run() error = %v", err)
+	}
+
+	if !ready {
+		t.Errorf("PortForwardSupervisor.Run() didn't call OnReady")
+	}
+
+	select {
+	case <-p.Done():
+	default:
+		t.Errorf("PortForwardSupervisor.Run() didn't stop the port-forward on shutdown")
+	}
+
+	for _, want := range []string{"Grafana: lost connection: lost connection to pod", "Grafana: failed to reconnect: pod not ready", "Grafana: reconnected", "Grafana: port-forward stopped"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("PortForwardSupervisor.Run() output = %q, want to contain %q", out.String(), want)
+		}
+	}
+}
+
+func TestPortForwardSupervisor_PodRestart(t *testing.T) {
+	// the connection of a real port-forward stays up when the pod
+	// restarts, only the pod monitor notices and tears it down
+	pods := watch.NewFake()
+	reconnected := make(chan *PortForward, 1)
+	calls := 0
+	forward := func() (*PortForward, error) {
+		calls++
+		p := fakePortForward(make(chan struct{}))
+		if calls == 1 {
+			go p.monitorPod(func() (watch.Interface, error) { return pods, nil }, testPod("1", 0))
+		} else {
+			reconnected <- p
+		}
+		return p, nil
+	}
+
+	var out bytes.Buffer
+	s := NewPortForwardSupervisor()
+	s.InitialBackoff = time.Millisecond
+	s.Out = &out
+	s.Add("Grafana", forward)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errChan := make(chan error)
+	go func() {
+		errChan <- s.Run(ctx)
+	}()
+
+	pods.Modify(testPod("1", 1))
+	select {
+	case <-reconnected:
+	case <-time.After(5 * time.Second):
+		t.Fatal("PortForwardSupervisor.Run() didn't reconnect after the pod restarted")
+	}
+
+	cancel()
+	if err := <-errChan; err != nil {
+		t.Errorf("PortForwardSupervisor.Run() error = %v", err)
+	}
+	if want := "Grafana: lost connection: pod tobs-grafana-0 restarted"; !strings.Contains(out.String(), want) {
+		t.Errorf("PortForwardSupervisor.Run() output = %q, want to contain %q", out.String(), want)
+	}
+}
+
+func TestPodLost(t *testing.T) {
+	terminating := testPod("1", 0)
+	terminating.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	failed := testPod("1", 0)
+	failed.Status.Phase = corev1.PodFailed
+
+	tests := []struct {
+		name  string
+		event watch.Event
+		want  string
+	}{
+		{name: "unchanged", event: watch.Event{Type: watch.Modified, Object: testPod("1", 0)}},
+		{name: "deleted", event: watch.Event{Type: watch.Deleted, Object: testPod("1", 0)}, want: "pod tobs-grafana-0 was deleted"},
+		{name: "replaced", event: watch.Event{Type: watch.Added, Object: testPod("2", 0)}, want: "pod tobs-grafana-0 was replaced"},
+		{name: "terminating", event: watch.Event{Type: watch.Modified, Object: terminating}, want: "pod tobs-grafana-0 is terminating"},
+		{name: "restarted", event: watch.Event{Type: watch.Modified, Object: testPod("1", 2)}, want: "pod tobs-grafana-0 restarted"},
+		{name: "failed", event: watch.Event{Type: watch.Modified, Object: failed}, want: "pod tobs-grafana-0 is Failed"},
+		{name: "watch error", event: watch.Event{Type: watch.Error, Object: &metav1.Status{}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := make(chan watch.Event, 1)
+			events <- tt.event
+			close(events)
+			lost, ok := podLost(events, make(chan struct{}), testPod("1", 1))
+			if !ok {
+				t.Fatal("podLost() ok = false, want true")
+			}
+			got := ""
+			if lost != nil {
+				got = lost.Error()
+			}
+			if got != tt.want {
+				t.Errorf("podLost() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	done := make(chan struct{})
+	close(done)
+	if _, ok := podLost(make(chan watch.Event), done, testPod("1", 0)); ok {
+		t.Error("podLost() ok = true after done was closed, want false")
+	}
+}
+
+func TestRunningPod(t *testing.T) {
+	terminating := *testPod("1", 0)
+	terminating.Name = "tobs-grafana-terminating"
+	terminating.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	pending := *testPod("2", 0)
+	pending.Name = "tobs-grafana-pending"
+	pending.Status.Phase = corev1.PodPending
+	running := *testPod("3", 0)
+
+	pod, ok := runningPod([]corev1.Pod{terminating, pending, running})
+	if !ok || pod.UID != "3" {
+		t.Errorf("runningPod() = %v, %v, want the running pod", pod, ok)
+	}
+
+	if pod, ok := runningPod([]corev1.Pod{terminating, pending}); ok {
+		t.Errorf("runningPod() = %v, want no pod", pod.Name)
+	}
+}
+
+func TestPortForwardFailWhileFinishing(t *testing.T) {
+	// the pod monitor can fail the port-forward while it stops on its own
+	p := &PortForward{stopChan: make(chan struct{}), done: make(chan struct{})}
+	go p.fail(errors.New("pod tobs-grafana-0 restarted"))
+	p.finish(errors.New("lost connection to pod"))
+	if p.Err() == nil {
+		t.Error("PortForward.Err() = nil, want an error")
+	}
+}
+
+func TestPortForwardSupervisor_StartFailure(t *testing.T) {
+	first := fakePortForward(make(chan struct{}))
+
+	s := NewPortForwardSupervisor()
+	s.OnReady = func() { t.Errorf("PortForwardSupervisor.Run() called OnReady after a failed start") }
+	s.Add("Grafana", func() (*PortForward, error) { return first, nil })
+	s.Add("Prometheus", func() (*PortForward, error) { return nil, errors.New("service not found") })
+
+	err := s.Run(context.Background())
+	if err == nil || err.Error() != "service not found" {
+		t.Errorf("PortForwardSupervisor.Run() error = %v, want service not found", err)
+	}
+
+	select {
+	case <-first.Done():
+	default:
+		t.Errorf("PortForwardSupervisor.Run() didn't stop the started port-forwards")
+	}
+}
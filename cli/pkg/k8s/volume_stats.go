@@ -0,0 +1,111 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// VolumeStats is the usage of the volume of a PVC as reported by the kubelet
+type VolumeStats struct {
+	PVCName        string `json:"pvcName"`
+	Namespace      string `json:"namespace"`
+	Pod            string `json:"pod"`
+	CapacityBytes  uint64 `json:"capacityBytes"`
+	AvailableBytes uint64 `json:"availableBytes"`
+}
+
+// FreePercent is the share of the volume which is still available
+func (v *VolumeStats) FreePercent() float64 {
+	if v.CapacityBytes == 0 {
+		return 0
+	}
+	return float64(v.AvailableBytes) * 100 / float64(v.CapacityBytes)
+}
+
+// statsSummary is the part of the kubelet stats/summary response with the volume usage
+type statsSummary struct {
+	Pods []struct {
+		PodRef struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"podRef"`
+		Volumes []struct {
+			CapacityBytes  *uint64 `json:"capacityBytes"`
+			AvailableBytes *uint64 `json:"availableBytes"`
+			PVCRef         *struct {
+				Name      string `json:"name"`
+				Namespace string `json:"namespace"`
+			} `json:"pvcRef"`
+		} `json:"volume"`
+	} `json:"pods"`
+}
+
+// parseVolumeStats returns the stats of the PVC volumes in the namespace from a kubelet stats summary
+func parseVolumeStats(data []byte, namespace string) ([]*VolumeStats, error) {
+	var summary statsSummary
+	err := json.Unmarshal(data, &summary)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse stats summary: %w", err)
+	}
+
+	var stats []*VolumeStats
+	for _, p := range summary.Pods {
+		for _, v := range p.Volumes {
+			if v.PVCRef == nil || v.PVCRef.Namespace != namespace || v.CapacityBytes == nil || v.AvailableBytes == nil {
+				continue
+			}
+			stats = append(stats, &VolumeStats{
+				PVCName:        v.PVCRef.Name,
+				Namespace:      v.PVCRef.Namespace,
+				Pod:            p.PodRef.Name,
+				CapacityBytes:  *v.CapacityBytes,
+				AvailableBytes: *v.AvailableBytes,
+			})
+		}
+	}
+	return stats, nil
+}
+
+// GetPVCVolumeStats returns the usage of the PVC volumes mounted by the pods in the
+// namespace, read from the stats summary of the kubelets running the pods
+func (c *clientImpl) GetPVCVolumeStats(namespace string) ([]*VolumeStats, error) {
+	pods, err := c.CoreV1().Pods(namespace).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	var stats []*VolumeStats
+	seen := make(map[string]bool)
+	for _, pod := range pods.Items {
+		node := pod.Spec.NodeName
+		if node == "" || seen[node] {
+			continue
+		}
+		seen[node] = true
+
+		data, err := c.CoreV1().RESTClient().Get().Resource("nodes").Name(node).
+			SubResource("proxy").Suffix("stats/summary").DoRaw(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("failed to get stats summary of node %s: %w", node, err)
+		}
+
+		nodeStats, err := parseVolumeStats(data, namespace)
+		if err != nil {
+			return nil, fmt.Errorf("node %s: %w", node, err)
+		}
+		stats = append(stats, nodeStats...)
+	}
+	return stats, nil
+}
+
+// GetServerVersion returns the Kubernetes version of the API server
+func (c *clientImpl) GetServerVersion() (string, error) {
+	v, err := c.Discovery().ServerVersion()
+	if err != nil {
+		return "", err
+	}
+	return v.GitVersion, nil
+}
@@ -0,0 +1,56 @@
+package k8s
+
+import "testing"
+
+const summary = `{
+  "node": {"nodeName": "kind-control-plane"},
+  "pods": [
+    {
+      "podRef": {"name": "tobs-timescaledb-0", "namespace": "default"},
+      "volume": [
+        {"name": "storage-volume", "capacityBytes": 1000, "availableBytes": 50,
+         "pvcRef": {"name": "storage-volume-tobs-timescaledb-0", "namespace": "default"}},
+        {"name": "kube-api-access", "capacityBytes": 2000, "availableBytes": 2000}
+      ]
+    },
+    {
+      "podRef": {"name": "other", "namespace": "other"},
+      "volume": [
+        {"name": "data", "capacityBytes": 1000, "availableBytes": 900,
+         "pvcRef": {"name": "data-other", "namespace": "other"}}
+      ]
+    },
+    {
+      "podRef": {"name": "tobs-prometheus-0", "namespace": "default"},
+      "volume": [
+        {"name": "prometheus", "pvcRef": {"name": "prometheus-pvc", "namespace": "default"}}
+      ]
+    }
+  ]
+}`
+
+func TestParseVolumeStats(t *testing.T) {
+	stats, err := parseVolumeStats([]byte(summary), "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// volumes without a PVC, in other namespaces or without usage are left out
+	if len(stats) != 1 {
+		t.Fatalf("expected 1 volume, got %d", len(stats))
+	}
+	s := stats[0]
+	if s.PVCName != "storage-volume-tobs-timescaledb-0" || s.Pod != "tobs-timescaledb-0" {
+		t.Errorf("unexpected volume %+v", s)
+	}
+	if s.FreePercent() != 5 {
+		t.Errorf("expected 5%% free, got %v", s.FreePercent())
+	}
+}
+
+func TestParseVolumeStats_Invalid(t *testing.T) {
+	_, err := parseVolumeStats([]byte("not json"), "default")
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
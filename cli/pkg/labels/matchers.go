@@ -0,0 +1,150 @@
+package labels
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	MatchEqual     = "="
+	MatchNotEqual  = "!="
+	MatchRegexp    = "=~"
+	MatchNotRegexp = "!~"
+)
+
+// Matcher is a single PromQL label matcher such as job="foo"
+type Matcher struct {
+	Name  string
+	Type  string
+	Value string
+}
+
+func (m Matcher) String() string {
+	return fmt.Sprintf("%s%s%q", m.Name, m.Type, m.Value)
+}
+
+// ParseMatchers parses PromQL style label matchers in the
+// form of {job="foo",instance=~"bar.*"}. The braces are optional.
+func ParseMatchers(s string) ([]Matcher, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "{") {
+		if !strings.HasSuffix(s, "}") {
+			return nil, fmt.Errorf("missing closing brace in label matchers %s", s)
+		}
+		s = s[1 : len(s)-1]
+	}
+
+	p := &parser{input: s}
+	var matchers []Matcher
+	for {
+		p.skipSpaces()
+		if p.done() {
+			break
+		}
+
+		m, err := p.matcher()
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, m)
+
+		p.skipSpaces()
+		if p.done() {
+			break
+		}
+		if p.input[p.pos] != ',' {
+			return nil, fmt.Errorf("expected , after matcher %v at position %d", m, p.pos)
+		}
+		p.pos++
+	}
+
+	return matchers, nil
+}
+
+type parser struct {
+	input string
+	pos   int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *parser) skipSpaces() {
+	for !p.done() && (p.input[p.pos] == ' ' || p.input[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *parser) matcher() (Matcher, error) {
+	var m Matcher
+
+	start := p.pos
+	for !p.done() && isLabelChar(p.input[p.pos], p.pos == start) {
+		p.pos++
+	}
+	m.Name = p.input[start:p.pos]
+	if m.Name == "" {
+		return m, fmt.Errorf("expected label name at position %d", start)
+	}
+
+	p.skipSpaces()
+	// the two character operators need to be checked first
+	for _, op := range []string{MatchNotEqual, MatchRegexp, MatchNotRegexp, MatchEqual} {
+		if strings.HasPrefix(p.input[p.pos:], op) {
+			m.Type = op
+			p.pos += len(op)
+			break
+		}
+	}
+	if m.Type == "" {
+		return m, fmt.Errorf("expected one of =, !=, =~, !~ after label %s", m.Name)
+	}
+
+	p.skipSpaces()
+	value, err := p.quoted()
+	if err != nil {
+		return m, fmt.Errorf("invalid value for label %s: %w", m.Name, err)
+	}
+	m.Value = value
+
+	return m, nil
+}
+
+// quoted reads a string quoted with double quotes, single quotes or backticks
+func (p *parser) quoted() (string, error) {
+	if p.done() {
+		return "", fmt.Errorf("expected quoted string at position %d", p.pos)
+	}
+
+	quote := p.input[p.pos]
+	if quote != '"' && quote != '\'' && quote != '`' {
+		return "", fmt.Errorf("expected quoted string at position %d", p.pos)
+	}
+
+	start := p.pos
+	p.pos++
+	for !p.done() {
+		c := p.input[p.pos]
+		if c == '\\' && quote != '`' {
+			p.pos += 2
+			continue
+		}
+		p.pos++
+		if c == quote {
+			raw := p.input[start:p.pos]
+			if quote == '\'' {
+				// strconv only unquotes single characters in single quotes
+				raw = `"` + strings.ReplaceAll(raw[1:len(raw)-1], `"`, `\"`) + `"`
+			}
+			return strconv.Unquote(raw)
+		}
+	}
+
+	return "", fmt.Errorf("unterminated quoted string at position %d", start)
+}
+
+func isLabelChar(c byte, first bool) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (!first && c >= '0' && c <= '9')
+}
@@ -0,0 +1,81 @@
+package labels
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMatchers(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []Matcher
+		wantErr bool
+	}{
+		{
+			name:  "All matcher types",
+			input: `{job="foo",instance=~"bar.*", env!="dev" ,region!~'us-.*'}`,
+			want: []Matcher{
+				{Name: "job", Type: MatchEqual, Value: "foo"},
+				{Name: "instance", Type: MatchRegexp, Value: "bar.*"},
+				{Name: "env", Type: MatchNotEqual, Value: "dev"},
+				{Name: "region", Type: MatchNotRegexp, Value: "us-.*"},
+			},
+		},
+		{
+			name:  "Without braces",
+			input: `job="foo"`,
+			want:  []Matcher{{Name: "job", Type: MatchEqual, Value: "foo"}},
+		},
+		{
+			name:  "Escaped quotes and trailing comma",
+			input: "{path=\"a\\\"b\",raw=`c\\d`,}",
+			want: []Matcher{
+				{Name: "path", Type: MatchEqual, Value: `a"b`},
+				{Name: "raw", Type: MatchEqual, Value: `c\d`},
+			},
+		},
+		{
+			name:  "Empty",
+			input: `{}`,
+			want:  nil,
+		},
+		{
+			name:    "Missing closing brace",
+			input:   `{job="foo"`,
+			wantErr: true,
+		},
+		{
+			name:    "Unquoted value",
+			input:   `{job=foo}`,
+			wantErr: true,
+		},
+		{
+			name:    "Unknown operator",
+			input:   `{job=="foo"}`,
+			wantErr: true,
+		},
+		{
+			name:    "Missing comma",
+			input:   `{job="foo" env="dev"}`,
+			wantErr: true,
+		},
+		{
+			name:    "Unterminated value",
+			input:   `{job="foo}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseMatchers(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseMatchers() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseMatchers() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package labels
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Promscale operators matching a label_array
+// against a label key and value
+var promscaleOperators = map[string]string{
+	MatchEqual:     "==",
+	MatchNotEqual:  "!==",
+	MatchRegexp:    "==~",
+	MatchNotRegexp: "!=~",
+}
+
+// AnchoredValue returns the value to match label values against. PromQL regular
+// expressions match the whole label value while Postgres ~ matches substrings,
+// so they are anchored like the Promscale querier does.
+func (m Matcher) AnchoredValue() string {
+	if m.Type == MatchRegexp || m.Type == MatchNotRegexp {
+		return "^(?:" + m.Value + ")$"
+	}
+	return m.Value
+}
+
+// SeriesCondition returns the SQL condition selecting the rows of _prom_catalog.series,
+// aliased as s, matching the matchers along with its arguments, numbered from firstArg
+func SeriesCondition(matchers []Matcher, firstArg int) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	for _, m := range matchers {
+		n := firstArg + len(args)
+		conditions = append(conditions, fmt.Sprintf("s.labels ? ($%d::TEXT %s $%d::TEXT)", n, promscaleOperators[m.Type], n+1))
+		args = append(args, m.Name, m.AnchoredValue())
+	}
+	return strings.Join(conditions, " AND "), args
+}
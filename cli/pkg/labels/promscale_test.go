@@ -0,0 +1,52 @@
+package labels
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestSeriesCondition(t *testing.T) {
+	matchers := []Matcher{
+		{Name: "job", Type: MatchEqual, Value: "foo"},
+		{Name: "instance", Type: MatchRegexp, Value: "bar.*"},
+		{Name: "region", Type: MatchNotRegexp, Value: "us|eu"},
+	}
+
+	got, args := SeriesCondition(matchers, 2)
+	want := "s.labels ? ($2::TEXT == $3::TEXT) AND s.labels ? ($4::TEXT ==~ $5::TEXT) AND s.labels ? ($6::TEXT !=~ $7::TEXT)"
+	if got != want {
+		t.Errorf("SeriesCondition() = %v, want %v", got, want)
+	}
+	wantArgs := []interface{}{"job", "foo", "instance", "^(?:bar.*)$", "region", "^(?:us|eu)$"}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("SeriesCondition() args = %v, want %v", args, wantArgs)
+	}
+}
+
+func TestAnchoredValue(t *testing.T) {
+	// the anchored values match like PromQL, not like an unanchored Postgres ~
+	tests := []struct {
+		value string
+		label string
+		want  bool
+	}{
+		{value: "bar.*", label: "bar1", want: true},
+		{value: "bar.*", label: "foobar1", want: false},
+		{value: "us|eu", label: "us", want: true},
+		{value: "us|eu", label: "eu-west", want: false},
+		{value: "", label: "", want: true},
+		{value: "", label: "x", want: false},
+	}
+	for _, tt := range tests {
+		m := Matcher{Name: "l", Type: MatchRegexp, Value: tt.value}
+		if got := regexp.MustCompile(m.AnchoredValue()).MatchString(tt.label); got != tt.want {
+			t.Errorf("%v matches %q = %v, want %v", m, tt.label, got, tt.want)
+		}
+	}
+
+	m := Matcher{Name: "l", Type: MatchEqual, Value: "bar.*"}
+	if got := m.AnchoredValue(); got != "bar.*" {
+		t.Errorf("AnchoredValue() = %v, want the value unchanged for equality matchers", got)
+	}
+}
@@ -0,0 +1,62 @@
+package migration
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+var (
+	// FIXME(paulfantom): if CRDs would contain label with version, we could deduct this value
+	// Needs https://github.com/prometheus-operator/prometheus-operator/issues/4344 to be completed
+	KubePrometheusCRDVersion     = "v0.56.2"
+	kubePrometheusCRDsPathPrefix = fmt.Sprintf("https://raw.githubusercontent.com/prometheus-operator/prometheus-operator/%s/example/prometheus-operator-crd/monitoring.coreos.com", KubePrometheusCRDVersion)
+	KubePrometheusCRDs           = map[string]string{
+		"alertmanagerconfigs.monitoring.coreos.com": fmt.Sprintf("%s_%s.yaml", kubePrometheusCRDsPathPrefix, "alertmanagerconfigs"),
+		"alertmanagers.monitoring.coreos.com":       fmt.Sprintf("%s_%s.yaml", kubePrometheusCRDsPathPrefix, "alertmanagers"),
+		"podmonitors.monitoring.coreos.com":         fmt.Sprintf("%s_%s.yaml", kubePrometheusCRDsPathPrefix, "podmonitors"),
+		"probes.monitoring.coreos.com":              fmt.Sprintf("%s_%s.yaml", kubePrometheusCRDsPathPrefix, "probes"),
+		"prometheuses.monitoring.coreos.com":        fmt.Sprintf("%s_%s.yaml", kubePrometheusCRDsPathPrefix, "prometheuses"),
+		"servicemonitors.monitoring.coreos.com":     fmt.Sprintf("%s_%s.yaml", kubePrometheusCRDsPathPrefix, "servicemonitors"),
+		"thanosrulers.monitoring.coreos.com":        fmt.Sprintf("%s_%s.yaml", kubePrometheusCRDsPathPrefix, "thanosrulers"),
+		"prometheusrules.monitoring.coreos.com":     fmt.Sprintf("%s_%s.yaml", kubePrometheusCRDsPathPrefix, "prometheusrules"),
+	}
+)
+
+// ApplyCRDs applies the CRDs as a step
+func (c *Context) ApplyCRDs(name string, crds map[string]string) error {
+	names := make([]string, 0, len(crds))
+	for crd := range crds {
+		names = append(names, crd)
+	}
+	sort.Strings(names)
+
+	return c.Step(StepCRDs, "apply the "+name+" CRDs", names, func() error {
+		err := c.K8sClient.ApplyManifests(crds)
+		if err != nil {
+			return fmt.Errorf("failed to apply manifest with error %v", err)
+		}
+
+		fmt.Println("Successfully created CRDs: ", reflect.ValueOf(crds).MapKeys())
+		return nil
+	})
+}
+
+// CRDs returns the CRDs the steps apply
+func CRDs(steps []Step) []string {
+	var crds []string
+	seen := make(map[string]bool)
+	for _, s := range steps {
+		if s.Kind != StepCRDs {
+			continue
+		}
+		for _, r := range s.Resources {
+			if !seen[r] {
+				seen[r] = true
+				crds = append(crds, r)
+			}
+		}
+	}
+	sort.Strings(crds)
+	return crds
+}
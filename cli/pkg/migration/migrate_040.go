@@ -0,0 +1,174 @@
+package migration
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/timescale/tobs/cli/pkg/utils"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	errors2 "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// kube-prometheus is introduced on tobs 0.4.0 release
+// replacing the prometheus chart and its node-exporter
+func init() {
+	// the CRDs and the node-exporter removal are also
+	// redone on upgrades of releases at 0.4.0
+	Register(Migration{
+		Name:      "node-exporter",
+		From:      "0.2.3",
+		To:        utils.Version_040,
+		Inclusive: true,
+		PreHook:   removeNodeExporter,
+	})
+	Register(Migration{
+		Name:    "kube-prometheus",
+		From:    "0.2.3",
+		To:      utils.Version_040,
+		PreHook: preUpgradeTo040,
+	})
+}
+
+// removeNodeExporter applies the Kube-Prometheus CRDs and deletes the
+// node-exporter of the prometheus chart, kube-prometheus deploys its own
+func removeNodeExporter(c *Context) error {
+	if !c.SkipCRDs {
+		// Kube-Prometheus CRDs
+		err := c.ApplyCRDs("Kube-Prometheus", KubePrometheusCRDs)
+		if err != nil {
+			return err
+		}
+	}
+
+	prometheusNodeExporter := c.ReleaseName + "-prometheus-node-exporter"
+	err := c.Step(StepDelete, "delete daemonset "+prometheusNodeExporter, nil, func() error {
+		err := c.K8sClient.DeleteDaemonset(prometheusNodeExporter, c.Namespace)
+		if err != nil && !errors2.IsNotFound(err) {
+			return fmt.Errorf("failed to delete %s daemonset %v", prometheusNodeExporter, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = c.Step(StepDelete, "delete service "+prometheusNodeExporter, nil, func() error {
+		err := c.K8sClient.KubeDeleteService(c.Namespace, prometheusNodeExporter)
+		if err != nil && !errors2.IsNotFound(err) {
+			return fmt.Errorf("failed to delete %s service %v", prometheusNodeExporter, err)
+		}
+		return nil
+	})
+	return err
+}
+
+func preUpgradeTo040(c *Context) error {
+	return c.Step(StepMigrate, "scale down Prometheus and move its persistent volume to the new Prometheus", nil, func() error {
+		return persistPrometheusDataDuringUpgrade(c)
+	})
+}
+
+func persistPrometheusDataDuringUpgrade(c *Context) error {
+	// scale down prometheus replicas to 0
+	fmt.Println("Migrating the underlying prometheus persistent volume to new prometheus instance...")
+	prometheus := c.ReleaseName + "-prometheus-server"
+	prometheusDeployment, err := c.K8sClient.GetDeployment(prometheus, c.Namespace)
+	if err != nil {
+		return fmt.Errorf("failed to get %s %v", prometheus, err)
+	}
+
+	fmt.Println("Scaling down prometheus instances to 0 replicas...")
+	var r int32 = 0
+	prometheusDeployment.Spec.Replicas = &r
+	err = c.K8sClient.UpdateDeployment(prometheusDeployment)
+	if err != nil {
+		return fmt.Errorf("failed to update %s %v", prometheus, err)
+	}
+
+	count := 0
+	for {
+		pods, err := c.K8sClient.KubeGetPods(c.Namespace, map[string]string{"app": "prometheus", "component": "server", "release": c.ReleaseName})
+		if err != nil {
+			return fmt.Errorf("unable to get pods from prometheus deployment %v", err)
+		}
+		if len(pods) == 0 {
+			break
+		}
+
+		if count == 10 {
+			return fmt.Errorf("prometheus pod shutdown saves all in memory data to persistent volume, prometheus pod is taking too long to shut down... ")
+		}
+		count++
+		time.Sleep(time.Duration(count*10) * time.Second)
+	}
+
+	// update existing prometheus PV to persist data and create a new PVC so the
+	// new prometheus mounts to the created PVC which binds to older prometheus PV.
+	err = c.K8sClient.UpdatePVToNewPVC(prometheus, utils.PrometheusPVCName, c.Namespace, map[string]string{"prometheus": "tobs-kube-prometheus", "release": c.ReleaseName})
+	if err != nil {
+		return fmt.Errorf("failed to update prometheus persistent volume %v", err)
+	}
+
+	// create job to update prometheus data directory permissions as the
+	// new prometheus expects the data dir to be owned by userid 1000.
+	fmt.Println("Create job to update prometheus data directory permissions...")
+	err = c.K8sClient.CreateJob(getJobForPrometheusDataPermissionChange(c.ReleaseName, c.Namespace, utils.PrometheusPVCName))
+	if err != nil {
+		return fmt.Errorf("failed to create job for prometheus data migration %v", err)
+	}
+
+	return nil
+}
+
+func getJobForPrometheusDataPermissionChange(releaseName, namespace, pvcName string) *batchv1.Job {
+	var backoff int32 = 3
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      utils.UpgradeJob_040,
+			Namespace: namespace,
+			Labels:    map[string]string{"app": "tobs-upgrade", "release": releaseName},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoff,
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{},
+				Spec: v1.PodSpec{
+					RestartPolicy: "OnFailure",
+					Containers: []v1.Container{
+						{
+							Name:            "upgrade-tobs",
+							Image:           "alpine",
+							ImagePullPolicy: v1.PullIfNotPresent,
+							Stdin:           true,
+							TTY:             true,
+							Command: []string{
+								"chown",
+								"1000:1000",
+								"-R",
+								"/data/",
+							},
+							VolumeMounts: []v1.VolumeMount{
+								{
+									Name:      "prometheus",
+									MountPath: "/data",
+								},
+							},
+						},
+					},
+					Volumes: []v1.Volume{
+						{
+							Name: "prometheus",
+							VolumeSource: v1.VolumeSource{
+								PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{
+									ClaimName: pvcName,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
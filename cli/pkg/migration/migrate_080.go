@@ -0,0 +1,322 @@
+package migration
+
+import (
+	"fmt"
+
+	"github.com/timescale/tobs/cli/pkg/otel"
+	errors2 "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// InputSuperuserPassword is the input with the password of the TimescaleDB superuser
+const InputSuperuserPassword = "superuser-password"
+
+// tobs 0.8.0 moves the database connection and tracing settings
+// of Promscale and upgrades kube-prometheus and cert-manager
+func init() {
+	Register(Migration{
+		Name:     "promscale-connection",
+		From:     "0.4.0",
+		To:       "0.8.0",
+		PreHook:  preUpgradeTo080,
+		Values:   valuesTo080,
+		PostHook: postUpgradeTo080,
+	})
+}
+
+func preUpgradeTo080(c *Context) error {
+	// capture existing TimescaleDB secret
+	e, err := c.HelmClient.ExportValuesFieldFromRelease(c.ReleaseName, []string{"timescaledb-single", "enabled"})
+	if err != nil {
+		return fmt.Errorf("failed to get is timescaledb enabled value %v", err)
+	}
+	isTSDBEnabled, ok := e.(bool)
+	if !ok {
+		return fmt.Errorf("timescaledb-single.enabled was not a bool")
+	}
+
+	if isTSDBEnabled {
+		tsdbSecret, err := c.K8sClient.KubeGetSecret(c.Namespace, c.ReleaseName+"-credentials")
+		if err != nil {
+			return fmt.Errorf("failed to get secret %v", err)
+		}
+		c.Inputs[InputSuperuserPassword] = string(tsdbSecret.Data["PATRONI_SUPERUSER_PASSWORD"])
+	}
+
+	// Delete kube-state-metrics as per kube-prometheus upgrade guide
+	err = c.Step(StepDelete, "delete the kube-state-metrics deployment", nil, func() error {
+		err := c.K8sClient.DeleteDeployment(map[string]string{"app.kubernetes.io/instance": c.ReleaseName,
+			"app.kubernetes.io/name": "kube-state-metrics"}, c.Namespace)
+		if err != nil {
+			return fmt.Errorf("failed to delete kube-state-metrics deployment %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Delete the grafana-db job to re-run the job on upgrade
+	// and the db job includes changes to spec in 0.8.0 version
+	grafanaJob := c.ReleaseName + "-grafana-db"
+	err = c.Step(StepDelete, "delete job "+grafanaJob, nil, func() error {
+		err := c.K8sClient.DeleteJob(grafanaJob, c.Namespace)
+		if err != nil && !errors2.IsNotFound(err) {
+			return fmt.Errorf("failed to delete %s job %v", grafanaJob, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// update Kube-Prometheus CRDs
+	err = c.ApplyCRDs("Kube-Prometheus", KubePrometheusCRDs)
+	if err != nil {
+		return err
+	}
+
+	isTracingEnabled, err := tracingEnabled(c.Values)
+	if err != nil {
+		return err
+	}
+	if !isTracingEnabled {
+		return nil
+	}
+
+	otelCol := otel.OtelCol{
+		ReleaseName: c.ReleaseName,
+		Namespace:   c.Namespace,
+		K8sClient:   c.K8sClient,
+		HelmClient:  c.HelmClient,
+	}
+
+	// apply OpenTelemetry CRDs
+	err = c.ApplyCRDs("OpenTelemetry", otel.OpenTelemetryCRDs)
+	if err != nil {
+		return err
+	}
+
+	config, err := c.HelmClient.ExportValuesFieldFromChart(c.ChartRef, c.ValuesFile, []string{"opentelemetryOperator", "collector", "config"})
+	if err != nil {
+		return err
+	}
+	otelColConfig, ok := config.(string)
+	if !ok {
+		return fmt.Errorf("opentelemetryOperator.collector.config is not the expected type: %T", config)
+	}
+
+	// cert-manager is upgraded by the post-hook
+	if c.Plan {
+		change, err := otelCol.PlanCertManager()
+		if err != nil {
+			return err
+		}
+		c.UpgradeCertManager = change != ""
+	} else if !c.DryRun {
+		err = otelCol.ValidateCertManager()
+		if err != nil {
+			return err
+		}
+		c.UpgradeCertManager = otelCol.UpgradeCM
+	}
+
+	err = c.Step(StepDelete, "delete the default OpenTelemetry collector", nil, otelCol.DeleteDefaultOtelCollector)
+	if err != nil {
+		return err
+	}
+
+	return c.Step(StepCreate, "create the default OpenTelemetry collector", nil, func() error {
+		return otelCol.CreateDefaultCollector(otelColConfig)
+	})
+}
+
+// upgrade cert-manager post upgrade process as
+// helm diff tries to evaluate resources with required APIVersions
+// upgrading cert-manager prior to helm upgrade prompts the below error
+//
+// Error: failed to upgrade current release manifest contains removed kubernetes api(s)
+// for this kubernetes version and it is therefore unable to build the kubernetes objects for performing the diff.
+// error from kubernetes: [unable to recognize "": no matches for kind "Certificate" in version "cert-manager.io/v1alpha2",
+// unable to recognize "": no matches for kind "Issuer" in version "cert-manager.io/v1alpha2"]
+//
+// This is expected as upgrade CM before helm upgrade doesn't support
+// he deprecated API's tha helm expects to have.
+func postUpgradeTo080(c *Context) error {
+	if !c.UpgradeCertManager {
+		return nil
+	}
+	return c.Step(StepCertManager, "upgrade cert-manager to "+otel.CertManagerVersion, nil, otel.UpgradeCertManager)
+}
+
+func tracingEnabled(values map[string]interface{}) (bool, error) {
+	promscaleValues, ok := values["promscale"].(map[string]interface{})
+	if !ok {
+		return false, nil
+	}
+	v, ok := promscaleValues["tracing"]
+	if !ok {
+		return false, nil
+	}
+	traceMap, ok := v.(map[string]interface{})
+	if !ok {
+		return false, fmt.Errorf("promscale.tracing is not the expected type: %T", v)
+	}
+	enabled, ok := traceMap["enabled"].(bool)
+	if !ok {
+		return false, fmt.Errorf("promscale.tracing.enabled is not the expected type: %T", traceMap["enabled"])
+	}
+	return enabled, nil
+}
+
+// valuesTo080 removes the timescaledbExternal section moving its db_uri to
+// promscale.connection.uri, and moves promscale.tracing to promscale.openTelemetry
+func valuesTo080(releaseValues map[string]interface{}, inputs map[string]string) error {
+	tsdbSecretValue := inputs[InputSuperuserPassword]
+
+	// delete timescaledbExternal section in values.yaml
+	var externalDBURI string
+	_, tsdbExternalExists := releaseValues["timescaledbExternal"]
+	if tsdbExternalExists {
+		timescaleDBExternal, ok := releaseValues["timescaledbExternal"].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("timescaledbExternal is not the expected type: %T", releaseValues["timescaledbExternal"])
+		}
+		if timescaleDBExternal != nil {
+			isExternalTsdbenabled, ok := timescaleDBExternal["enabled"].(bool)
+			if !ok {
+				return fmt.Errorf("timescaledbExternal.enabled is not the expected type: %T", timescaleDBExternal["enabled"])
+			}
+			if isExternalTsdbenabled {
+				externalDBURI, ok = timescaleDBExternal["db_uri"].(string)
+				if !ok {
+					return fmt.Errorf("timescaledbExternal.db_uri is not the expected type: %T", timescaleDBExternal["db_uri"])
+				}
+			}
+			delete(releaseValues, "timescaledbExternal")
+		}
+	}
+
+	isTracingEnabled, err := tracingEnabled(releaseValues)
+	if err != nil {
+		return err
+	}
+
+	// refactor promscale section in values.yaml
+	var ok bool
+	var promscaleValues map[string]interface{}
+	_, promscaleExists := releaseValues["promscale"]
+	if promscaleExists {
+		promscaleValues, ok = releaseValues["promscale"].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("promscale is not the expected type: %T", releaseValues["promscale"])
+		}
+	}
+
+	if !promscaleExists {
+		// promscale values is nil, construct the spec to
+		// assign db password/uri
+		releaseValues["promscale"] = make(map[string]interface{})
+		promscaleValues = releaseValues["promscale"].(map[string]interface{})
+		promscaleValues["connection"] = make(map[string]interface{})
+		connValues := promscaleValues["connection"].(map[string]interface{})
+		connValues["uri"] = externalDBURI
+		connValues["password"] = tsdbSecretValue
+	} else {
+		for k1, v1 := range promscaleValues {
+			if k1 == "tracing" {
+				promscaleValues["openTelemetry"] = v1
+				// drop older tracing field which is no longer used
+				delete(promscaleValues, "tracing")
+			}
+
+			if k1 == "image" {
+				imageName, ok := v1.(string)
+				if !ok {
+					return fmt.Errorf("promscale.image is not the expected type: %T", v1)
+				}
+				// In tobs 0.7.0 release we hardcoded
+				// beta release if tracing is enabled, from 0.8.0 tobs
+				// release all default images will include out of the tracing support in
+				// all Promscale images.
+				if imageName == "timescale/promscale:0.7.0-beta.latest" {
+					delete(promscaleValues, "image")
+				}
+			}
+
+			if k1 == "args" {
+				aV, ok := v1.([]interface{})
+				if !ok {
+					return fmt.Errorf("promscale.args is not the expected type: %T", v1)
+				}
+				args := make([]interface{}, 0, len(aV))
+				for _, value := range aV {
+					if value == "-otlp-grpc-server-listen-address=:9202" {
+						continue
+					}
+					// if HA arg is found in Promscale
+					// change it to new HA arg.
+					if value == "--high-availability" {
+						value = "--metrics.high-availability"
+					}
+					args = append(args, value)
+				}
+				promscaleValues["args"] = args
+			}
+
+			if k1 == "connection" {
+				connectionValues, ok := v1.(map[string]interface{})
+				if !ok {
+					return fmt.Errorf("promscale.connection is not the expected type: %T", v1)
+				}
+
+				connectionValues["uri"] = externalDBURI
+
+				for k2, v2 := range connectionValues {
+					if k2 == "password" {
+						connectionValues["password"] = tsdbSecretValue
+					}
+
+					if k2 == "host" {
+						hostValues, ok := v2.(map[string]interface{})
+						if !ok {
+							return fmt.Errorf("promscale.connection.host is not the expected type: %T", v2)
+						}
+						hostValue := hostValues["nameTemplate"]
+						connectionValues["host"] = hostValue
+					}
+				}
+			}
+
+			if k1 == "service" {
+				promService, ok := v1.(map[string]interface{})
+				if !ok {
+					return fmt.Errorf("promscale.service is not the expected type: %T", v1)
+				}
+				lbValues := promService["loadBalancer"]
+				lb, ok := lbValues.(map[string]interface{})
+				if !ok {
+					return fmt.Errorf("promscale.service.loadBalancer is not the expected type: %T", lbValues)
+				}
+				lbEnabled := lb["enabled"]
+				if lbEnabled == "true" {
+					promService["type"] = "LoadBalancer"
+				} else {
+					promService["type"] = "ClusterIP"
+				}
+				delete(promService, "loadBalancer")
+			}
+		}
+	}
+
+	if isTracingEnabled {
+		// re-structure jaeger values
+		otelValues, ok := releaseValues["opentelemetryOperator"].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("opentelemetryOperator is not the expected type: %T", releaseValues["opentelemetryOperator"])
+		}
+		// delete jaegerPromscaleQuery as it's no-longer used in values.yaml
+		delete(otelValues, "jaegerPromscaleQuery")
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package migration
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValuesTo080(t *testing.T) {
+	values := map[string]interface{}{
+		"timescaledbExternal": map[string]interface{}{
+			"enabled": true,
+			"db_uri":  "postgres://user:pass@db:5432/tsdb",
+		},
+		"promscale": map[string]interface{}{
+			"image": "timescale/promscale:0.7.0-beta.latest",
+			"tracing": map[string]interface{}{
+				"enabled": true,
+			},
+			"args": []interface{}{"-otlp-grpc-server-listen-address=:9202", "--high-availability"},
+			"connection": map[string]interface{}{
+				"password": "",
+				"host": map[string]interface{}{
+					"nameTemplate": "tobs.default.svc",
+				},
+			},
+			"service": map[string]interface{}{
+				"loadBalancer": map[string]interface{}{
+					"enabled": "true",
+				},
+			},
+		},
+		"opentelemetryOperator": map[string]interface{}{
+			"enabled":              true,
+			"jaegerPromscaleQuery": map[string]interface{}{"enabled": true},
+		},
+	}
+
+	err := valuesTo080(values, map[string]string{InputSuperuserPassword: "s3cret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]interface{}{
+		"promscale": map[string]interface{}{
+			"openTelemetry": map[string]interface{}{
+				"enabled": true,
+			},
+			"args": []interface{}{"--metrics.high-availability"},
+			"connection": map[string]interface{}{
+				"uri":      "postgres://user:pass@db:5432/tsdb",
+				"password": "s3cret",
+				"host":     "tobs.default.svc",
+			},
+			"service": map[string]interface{}{
+				"type": "LoadBalancer",
+			},
+		},
+		"opentelemetryOperator": map[string]interface{}{
+			"enabled": true,
+		},
+	}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("valuesTo080() got =\n%v\nwant =\n%v", values, want)
+	}
+}
+
+func TestValuesTo080WithoutPromscale(t *testing.T) {
+	values := map[string]interface{}{}
+	err := valuesTo080(values, map[string]string{InputSuperuserPassword: "s3cret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]interface{}{
+		"promscale": map[string]interface{}{
+			"connection": map[string]interface{}{
+				"uri":      "",
+				"password": "s3cret",
+			},
+		},
+	}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("valuesTo080() got = %v, want %v", values, want)
+	}
+}
+
+func TestValuesTo080InvalidType(t *testing.T) {
+	values := map[string]interface{}{
+		"promscale": map[string]interface{}{
+			"tracing": "enabled",
+		},
+	}
+	err := valuesTo080(values, map[string]string{})
+	if err == nil {
+		t.Fatal("expected error for invalid promscale.tracing")
+	}
+}
@@ -0,0 +1,244 @@
+package migration
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/timescale/tobs/cli/pkg/helm"
+	"github.com/timescale/tobs/cli/pkg/k8s"
+	"github.com/timescale/tobs/cli/pkg/utils"
+)
+
+// kinds of the steps of a migration
+const (
+	StepDelete      = "delete"
+	StepCreate      = "create"
+	StepMigrate     = "migrate"
+	StepCRDs        = "crds"
+	StepValues      = "values"
+	StepCertManager = "cert-manager"
+	StepUpgrade     = "upgrade"
+)
+
+// Step is a change a migration makes besides the helm upgrade
+type Step struct {
+	Kind        string   `json:"kind"`
+	Description string   `json:"description"`
+	Resources   []string `json:"resources,omitempty"`
+}
+
+// Context is the upgrade the migrations run in
+type Context struct {
+	ReleaseName string
+	Namespace   string
+	K8sClient   k8s.Client
+	HelmClient  helm.Client
+	// ChartRef and ValuesFile are the chart and values file upgraded to
+	ChartRef   string
+	ValuesFile string
+	SkipCRDs   bool
+	// Plan and DryRun record the steps without taking them,
+	// on Plan the hooks also must not prompt
+	Plan   bool
+	DryRun bool
+
+	// Values are the release values the transforms migrate
+	Values map[string]interface{}
+	// Inputs are collected from the cluster by the pre-hooks for the values transforms
+	Inputs map[string]string
+	// Steps are the steps taken, or on Plan and DryRun to be taken, in order
+	Steps []Step
+	// UpgradeCertManager is set by the pre-hooks when cert-manager
+	// needs an upgrade, which the post-hooks make
+	UpgradeCertManager bool
+}
+
+// Step records a change to the cluster and makes it, unless the upgrade is only planned or a dry-run
+func (c *Context) Step(kind, description string, resources []string, run func() error) error {
+	c.Steps = append(c.Steps, Step{Kind: kind, Description: description, Resources: resources})
+	if c.Plan || c.DryRun {
+		return nil
+	}
+	return run()
+}
+
+// Hook makes the changes to the cluster a migration needs besides the values
+type Hook func(c *Context) error
+
+// ValuesTransform migrates the release values to the ones the new chart version expects
+type ValuesTransform func(values map[string]interface{}, inputs map[string]string) error
+
+// Migration handles the breaking changes of a chart version
+type Migration struct {
+	// Name describes the migration in plans and errors
+	Name string
+	// From is the lowest version the release can be at when the migration runs
+	From string
+	// To is the version with the breaking changes, the migration runs
+	// on upgrades from a version lower than To to To or later
+	To string
+	// Values, PreHook and PostHook are optional. The pre-hook runs before the values
+	// transform and the post-hook after the helm upgrade.
+	Values   ValuesTransform
+	PreHook  Hook
+	PostHook Hook
+	// Inclusive migrations also run on upgrades from To to a later version
+	Inclusive bool
+}
+
+var registry []Migration
+
+// Register adds the migration to the ones Hops picks from
+func Register(m Migration) {
+	registry = append(registry, m)
+}
+
+// MinimumVersion is the oldest chart version releases can be upgraded from
+const MinimumVersion = "0.2.3"
+
+// Hop is a helm upgrade to Version with the migrations to run for it
+type Hop struct {
+	Version    string
+	Migrations []Migration
+}
+
+// Hops splits the upgrade from deployed to target into the helm upgrades it needs. When a migration
+// needs a later version than the one the previous migration leaves the release at, the release
+// is first upgraded to the From version of the migration.
+func Hops(deployed, target string) ([]Hop, error) {
+	return hops(registry, deployed, target)
+}
+
+func hops(migrations []Migration, deployed, target string) ([]Hop, error) {
+	dVersion, err := utils.ParseVersion(deployed, 3)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse deployed version %w", err)
+	}
+	tVersion, err := utils.ParseVersion(target, 3)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse target version %w", err)
+	}
+	minVersion, err := utils.ParseVersion(MinimumVersion, 3)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse minimum version %w", err)
+	}
+	// the migrations may not cover the versions up to the target, the
+	// upgrades from before the minimum version are rejected regardless
+	if dVersion < minVersion && tVersion >= minVersion {
+		return nil, fmt.Errorf("upgrade from version below %s is no longer supported in this tobs version. "+
+			"Please use older tobs binary to do a step-by-step upgrade", MinimumVersion)
+	}
+
+	type versioned struct {
+		m        Migration
+		from, to int64
+	}
+	var selected []versioned
+	for _, m := range migrations {
+		to, err := utils.ParseVersion(m.To, 3)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: failed to parse to version %w", m.Name, err)
+		}
+		from, err := utils.ParseVersion(m.From, 3)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: failed to parse from version %w", m.Name, err)
+		}
+		if (dVersion < to || m.Inclusive && dVersion == to && to < tVersion) && to <= tVersion {
+			selected = append(selected, versioned{m, from, to})
+		}
+	}
+	sort.SliceStable(selected, func(i, j int) bool { return selected[i].to < selected[j].to })
+
+	// each migration leaves the release at its To version for the next one
+	current := dVersion
+	var out []Hop
+	hop := Hop{Version: target}
+	for i, v := range selected {
+		if current < v.from {
+			// the migrations for versions older than the first one aren't registered anymore
+			if i == 0 {
+				return nil, fmt.Errorf("upgrade from version %s to %s is no longer supported in this tobs version, "+
+					"the %s migration needs version %s or later. Please use older tobs binary to do a step-by-step upgrade", deployed, target, v.m.Name, v.m.From)
+			}
+			hop.Version = v.m.From
+			out = append(out, hop)
+			hop = Hop{Version: target}
+			current = v.from
+		}
+		current = v.to
+		hop.Migrations = append(hop.Migrations, v.m)
+	}
+	return append(out, hop), nil
+}
+
+// IntermediateVersions returns the versions the hops upgrade to before the last one
+func IntermediateVersions(hops []Hop) []string {
+	var versions []string
+	for i := 0; i < len(hops)-1; i++ {
+		versions = append(versions, hops[i].Version)
+	}
+	return versions
+}
+
+// PreUpgrade runs the pre-hooks and the values transforms of the migrations in order,
+// recording the changes the transforms make to the values as steps
+func PreUpgrade(c *Context, migrations []Migration) error {
+	if c.Inputs == nil {
+		c.Inputs = make(map[string]string)
+	}
+	for _, m := range migrations {
+		if m.PreHook != nil {
+			err := m.PreHook(c)
+			if err != nil {
+				return fmt.Errorf("failed to perform upgrade path to %s %v", m.To, err)
+			}
+		}
+
+		if m.Values == nil {
+			continue
+		}
+		before, err := copyValues(c.Values)
+		if err != nil {
+			return err
+		}
+		err = m.Values(c.Values, c.Inputs)
+		if err != nil {
+			return fmt.Errorf("failed to migrate values to %s %v", m.To, err)
+		}
+		after, err := copyValues(c.Values)
+		if err != nil {
+			return err
+		}
+		for _, d := range helm.DiffValues(before, after) {
+			c.Steps = append(c.Steps, Step{Kind: StepValues, Description: fmt.Sprintf("%s: %s %s", m.To, d.Action, d.Path)})
+		}
+	}
+	return nil
+}
+
+// PostUpgrade runs the post-hooks of the migrations in order
+func PostUpgrade(c *Context, migrations []Migration) error {
+	for _, m := range migrations {
+		if m.PostHook == nil {
+			continue
+		}
+		err := m.PostHook(c)
+		if err != nil {
+			return fmt.Errorf("failed to complete upgrade path to %s %v", m.To, err)
+		}
+	}
+	return nil
+}
+
+// copyValues returns a deep copy of the values with the
+// numbers in one representation so copies compare equal
+func copyValues(values map[string]interface{}) (map[string]interface{}, error) {
+	b, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]interface{})
+	err = json.Unmarshal(b, &out)
+	return out, err
+}
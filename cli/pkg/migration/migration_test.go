@@ -0,0 +1,215 @@
+package migration
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var testMigrations = []Migration{
+	{Name: "c", From: "0.5.0", To: "0.8.0"},
+	{Name: "a", From: "0.2.3", To: "0.4.0"},
+	{Name: "b", From: "0.4.0", To: "0.6.0"},
+}
+
+func names(migrations []Migration) []string {
+	var n []string
+	for _, m := range migrations {
+		n = append(n, m.Name)
+	}
+	return n
+}
+
+// singleHop returns the migrations of the hops, failing when the upgrade needs more than one hop
+func singleHop(t *testing.T, h []Hop) []Migration {
+	t.Helper()
+	if len(h) != 1 {
+		t.Fatalf("got %d hops, want a single hop", len(h))
+	}
+	return h[0].Migrations
+}
+
+func TestHopsSingle(t *testing.T) {
+	tests := []struct {
+		deployed, target string
+		want             []string
+		wantErr          bool
+	}{
+		{deployed: "0.3.0", target: "0.8.1", want: []string{"a", "b", "c"}},
+		{deployed: "0.4.0", target: "0.8.0", want: []string{"b", "c"}},
+		{deployed: "0.5.2", target: "0.7.0", want: []string{"b"}},
+		{deployed: "0.8.0", target: "0.9.0", want: nil},
+		// same version upgrades only change values
+		{deployed: "0.6.0", target: "0.6.0", want: nil},
+		// the first migration doesn't support upgrading from 0.2.2
+		{deployed: "0.2.2", target: "0.8.0", wantErr: true},
+		// upgrades from before the minimum version fail even without migrations
+		{deployed: "0.2.2", target: "0.3.0", wantErr: true},
+		{deployed: "0.2.1", target: "0.2.2", want: nil},
+		{deployed: "invalid", target: "0.8.0", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := hops(testMigrations, tt.deployed, tt.target)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("hops(%s, %s) error = %v, wantErr %v", tt.deployed, tt.target, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if m := singleHop(t, got); !reflect.DeepEqual(names(m), tt.want) {
+			t.Errorf("hops(%s, %s) = %v, want %v", tt.deployed, tt.target, names(m), tt.want)
+		}
+	}
+}
+
+func TestHopsFromAfterPreviousMigration(t *testing.T) {
+	// b needs 0.5.0 while a only brings the release to 0.4.0
+	migrations := []Migration{
+		{Name: "a", From: "0.1.0", To: "0.4.0"},
+		{Name: "b", From: "0.5.0", To: "0.6.0"},
+	}
+	got, err := hops(migrations, "0.3.0", "0.6.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"0.5.0"}; !reflect.DeepEqual(IntermediateVersions(got), want) {
+		t.Errorf("IntermediateVersions() = %v, want %v", IntermediateVersions(got), want)
+	}
+}
+
+func TestHops(t *testing.T) {
+	// b needs 0.5.0 while a only brings the release to 0.4.0
+	migrations := []Migration{
+		{Name: "a", From: "0.1.0", To: "0.4.0"},
+		{Name: "b", From: "0.5.0", To: "0.6.0"},
+		{Name: "c", From: "0.6.0", To: "0.7.0"},
+	}
+
+	tests := []struct {
+		deployed, target string
+		want             []string
+		wantErr          bool
+	}{
+		{deployed: "0.3.0", target: "0.8.0", want: []string{"0.5.0: a", "0.8.0: b c"}},
+		{deployed: "0.5.0", target: "0.8.0", want: []string{"0.8.0: b c"}},
+		{deployed: "0.3.0", target: "0.4.0", want: []string{"0.4.0: a"}},
+		{deployed: "0.8.0", target: "0.9.0", want: []string{"0.9.0: "}},
+		{deployed: "0.0.1", target: "0.8.0", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := hops(migrations, tt.deployed, tt.target)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("hops(%s, %s) error = %v, wantErr %v", tt.deployed, tt.target, err, tt.wantErr)
+			continue
+		}
+		var hops []string
+		for _, h := range got {
+			hops = append(hops, h.Version+": "+strings.Join(names(h.Migrations), " "))
+		}
+		if !reflect.DeepEqual(hops, tt.want) {
+			t.Errorf("hops(%s, %s) = %v, want %v", tt.deployed, tt.target, hops, tt.want)
+		}
+	}
+
+	got, _ := hops(migrations, "0.3.0", "0.8.0")
+	if want := []string{"0.5.0"}; !reflect.DeepEqual(IntermediateVersions(got), want) {
+		t.Errorf("IntermediateVersions() = %v, want %v", IntermediateVersions(got), want)
+	}
+}
+
+func TestRegisteredHops(t *testing.T) {
+	h, err := Hops("0.3.0", "0.8.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := singleHop(t, h)
+	if want := []string{"node-exporter", "kube-prometheus", "promscale-connection"}; !reflect.DeepEqual(names(got), want) {
+		t.Errorf("Hops() = %v, want %v", names(got), want)
+	}
+
+	// releases at 0.4.0 still get the node-exporter removed
+	h, err = Hops("0.4.0", "0.8.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = singleHop(t, h)
+	if want := []string{"node-exporter", "promscale-connection"}; !reflect.DeepEqual(names(got), want) {
+		t.Errorf("Hops() = %v, want %v", names(got), want)
+	}
+
+	h, err = Hops("0.4.0", "0.4.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got = singleHop(t, h); len(got) != 0 {
+		t.Errorf("Hops() = %v, want no migrations for a same version upgrade", names(got))
+	}
+
+	_, err = Hops("0.2.2", "0.8.0")
+	if err == nil {
+		t.Error("expected upgrade from 0.2.2 to fail")
+	}
+}
+
+func TestPreUpgrade(t *testing.T) {
+	var order []string
+	migrations := []Migration{
+		{
+			Name: "a", From: "0.1.0", To: "0.2.0",
+			PreHook: func(c *Context) error {
+				order = append(order, "pre a")
+				c.Inputs["password"] = "s3cret"
+				return c.Step(StepDelete, "delete something", nil, func() error {
+					return errors.New("steps must not run on plan")
+				})
+			},
+			Values: func(values map[string]interface{}, inputs map[string]string) error {
+				order = append(order, "values a")
+				values["password"] = inputs["password"]
+				delete(values, "old")
+				return nil
+			},
+		},
+		{
+			Name: "b", From: "0.2.0", To: "0.3.0",
+			PreHook: func(c *Context) error {
+				order = append(order, "pre b")
+				return nil
+			},
+		},
+	}
+
+	c := &Context{Plan: true, Values: map[string]interface{}{"old": 1, "password": "a"}}
+	err := PreUpgrade(c, migrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"pre a", "values a", "pre b"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("order = %v, want %v", order, want)
+	}
+	if want := map[string]interface{}{"password": "s3cret"}; !reflect.DeepEqual(c.Values, want) {
+		t.Errorf("values = %v, want %v", c.Values, want)
+	}
+	want := []Step{
+		{Kind: StepDelete, Description: "delete something"},
+		{Kind: StepValues, Description: "0.2.0: removed old"},
+		{Kind: StepValues, Description: "0.2.0: modified password"},
+	}
+	if !reflect.DeepEqual(c.Steps, want) {
+		t.Errorf("steps = %v, want %v", c.Steps, want)
+	}
+}
+
+func TestCRDs(t *testing.T) {
+	steps := []Step{
+		{Kind: StepCRDs, Resources: []string{"b", "a"}},
+		{Kind: StepDelete, Resources: []string{"c"}},
+		{Kind: StepCRDs, Resources: []string{"a", "d"}},
+	}
+	if got, want := CRDs(steps), []string{"a", "b", "d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("CRDs() = %v, want %v", got, want)
+	}
+}
@@ -20,7 +20,7 @@ const (
 	CertManagerNamespace  = "cert-manager"
 	otelColKind           = "OpenTelemetryCollector"
 	otelColApiVersion     = "opentelemetry.io/v1alpha1"
-	otelOperatorNamespace = "opentelemetry-operator-system"
+	OtelOperatorNamespace = "opentelemetry-operator-system"
 	otelColResourceName   = "opentelemetrycollectors"
 )
 
@@ -132,22 +132,9 @@ func (c *OtelCol) ValidateCertManager() error {
 		// cert-manager, and error out stating these resources need a manual
 		// upgrade before completing the upgrade
 
-		certManagerResources, err := c.K8sClient.ListCertManagerDeprecatedCRs()
+		onlyCertMResources, err := c.listUnmanagedCertManagerResources()
 		if err != nil {
-			return fmt.Errorf("failed to list certificate custom resources %v", err)
-		}
-
-		var onlyCertMResources []k8s.ResourceDetails
-		for _, resource := range certManagerResources {
-			// the listCMDeprecated resources gets them from otel-operator
-			// namespace this needs to be ignored as otel-operator helm-chart upgrades them
-			// Mote: Resource with name 'opentelemetry-operator-selfsigned-issuer` isn't part of
-			// any namespace so adding a check.
-			if resource.Namespace != "opentelemetry-operator-system" {
-				if resource.Name != "opentelemetry-operator-selfsigned-issuer" {
-					onlyCertMResources = append(onlyCertMResources, resource)
-				}
-			}
+			return err
 		}
 
 		if len(onlyCertMResources) > 0 {
@@ -178,6 +165,65 @@ func (c *OtelCol) ValidateCertManager() error {
 	return nil
 }
 
+// PlanCertManager returns the change ValidateCertManager makes to cert-manager, empty
+// when there is none, without prompting. It fails in the same cases as ValidateCertManager.
+func (c *OtelCol) PlanCertManager() (string, error) {
+	certMInstalled, err := c.IsCertManagerInstalledByTobs()
+	if err != nil {
+		return "", fmt.Errorf("couldn't find the cert-manager status %v", err)
+	}
+
+	cmVersion, err := c.GetCertManagerVersion()
+	if err != nil {
+		return "", err
+	}
+	ok, err := isDesiredVersion(cmVersion, CertManagerVersion)
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		return "", nil
+	}
+
+	if !certMInstalled {
+		return "", fmt.Errorf("existing cert-manager in the cluster with version %s doesn't comply with the"+
+			" OpenTelemetry Operator, requires cert-manager version %s", cmVersion, CertManagerVersion)
+	}
+
+	resources, err := c.listUnmanagedCertManagerResources()
+	if err != nil {
+		return "", err
+	}
+	if len(resources) > 0 {
+		return "", fmt.Errorf("%d cert-manager resources not owned by tobs needs manual upgrade to cert-manager %s", len(resources), CertManagerVersion)
+	}
+
+	return fmt.Sprintf("upgrade cert-manager from %s to %s", cmVersion, CertManagerVersion), nil
+}
+
+// listUnmanagedCertManagerResources lists the cert-manager resources with deprecated
+// APIs which aren't upgraded along with tobs and need a manual upgrade
+func (c *OtelCol) listUnmanagedCertManagerResources() ([]k8s.ResourceDetails, error) {
+	certManagerResources, err := c.K8sClient.ListCertManagerDeprecatedCRs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list certificate custom resources %v", err)
+	}
+
+	var onlyCertMResources []k8s.ResourceDetails
+	for _, resource := range certManagerResources {
+		// the listCMDeprecated resources gets them from otel-operator
+		// namespace this needs to be ignored as otel-operator helm-chart upgrades them
+		// Mote: Resource with name 'opentelemetry-operator-selfsigned-issuer` isn't part of
+		// any namespace so adding a check.
+		if resource.Namespace != "opentelemetry-operator-system" {
+			if resource.Name != "opentelemetry-operator-selfsigned-issuer" {
+				onlyCertMResources = append(onlyCertMResources, resource)
+			}
+		}
+	}
+	return onlyCertMResources, nil
+}
+
 func UpgradeCertManager() error {
 	err := createUpgradeCertManager()
 	if err != nil {
@@ -252,11 +298,11 @@ func (c *OtelCol) GetCertManagerVersion() (string, error) {
 func (c *OtelCol) CreateDefaultCollector(otelColConfig string) error {
 	// check the status of otel operator as CR creation needs webhooks
 	// validation from operator
-	otelOperatorPod, err := c.K8sClient.KubeGetPodName(otelOperatorNamespace, map[string]string{"control-plane": "controller-manager"})
+	otelOperatorPod, err := c.K8sClient.KubeGetPodName(OtelOperatorNamespace, map[string]string{"control-plane": "controller-manager"})
 	if err != nil {
 		return fmt.Errorf("failed to find otel operator: %v", err)
 	}
-	err = c.K8sClient.KubeWaitOnPod(otelOperatorNamespace, otelOperatorPod)
+	err = c.K8sClient.KubeWaitOnPod(OtelOperatorNamespace, otelOperatorPod)
 	if err != nil {
 		return err
 	}
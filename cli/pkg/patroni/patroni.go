@@ -0,0 +1,98 @@
+package patroni
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+const (
+	RoleLeader        = "Leader"
+	RoleStandbyLeader = "Standby Leader"
+	RoleReplica       = "Replica"
+	RoleSyncStandby   = "Sync Standby"
+)
+
+// Member is a member of the Patroni cluster as listed by patronictl list --format json
+type Member struct {
+	Cluster  string `json:"cluster"`
+	Name     string `json:"member"`
+	Host     string `json:"host"`
+	Role     string `json:"role"`
+	State    string `json:"state"`
+	Timeline int    `json:"timeline"`
+	// LagMB is the replication lag in MB, nil for the leader and when it's unknown
+	LagMB          *float64 `json:"lagMB,omitempty"`
+	PendingRestart bool     `json:"pendingRestart"`
+}
+
+// IsLeader returns whether the member is the leader of the cluster
+func (m Member) IsLeader() bool {
+	return m.Role == RoleLeader || m.Role == RoleStandbyLeader
+}
+
+// patronictlMember is the json format of patronictl
+type patronictlMember struct {
+	Cluster        string          `json:"Cluster"`
+	Member         string          `json:"Member"`
+	Host           string          `json:"Host"`
+	Role           string          `json:"Role"`
+	State          string          `json:"State"`
+	TL             json.RawMessage `json:"TL"`
+	Lag            json.RawMessage `json:"Lag in MB"`
+	PendingRestart string          `json:"Pending restart"`
+}
+
+// ParseMembers parses the output of patronictl list --format json
+func ParseMembers(data []byte) ([]Member, error) {
+	var list []patronictlMember
+	err := json.Unmarshal(data, &list)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse patronictl list: %w", err)
+	}
+
+	members := make([]Member, 0, len(list))
+	for _, p := range list {
+		m := Member{
+			Cluster:        p.Cluster,
+			Name:           p.Member,
+			Host:           p.Host,
+			Role:           p.Role,
+			State:          p.State,
+			PendingRestart: p.PendingRestart != "",
+		}
+		// the timeline and lag are empty strings or
+		// "unknown" when patroni doesn't know them
+		if tl, ok := parseNumber(p.TL); ok {
+			m.Timeline = int(tl)
+		}
+		if lag, ok := parseNumber(p.Lag); ok && !m.IsLeader() {
+			m.LagMB = &lag
+		}
+		members = append(members, m)
+	}
+	return members, nil
+}
+
+func parseNumber(raw json.RawMessage) (float64, bool) {
+	var n float64
+	if json.Unmarshal(raw, &n) == nil {
+		return n, true
+	}
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		n, err := strconv.ParseFloat(s, 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+// GetLeader returns the leader of the cluster
+func GetLeader(members []Member) (*Member, error) {
+	for i := range members {
+		if members[i].IsLeader() {
+			return &members[i], nil
+		}
+	}
+	return nil, fmt.Errorf("the cluster has no leader")
+}
@@ -0,0 +1,41 @@
+package patroni
+
+import (
+	"testing"
+)
+
+const listJSON = `[
+  {"Cluster": "tobs", "Member": "tobs-timescaledb-0", "Host": "10.0.0.1", "Role": "Leader", "State": "running", "TL": 2},
+  {"Cluster": "tobs", "Member": "tobs-timescaledb-1", "Host": "10.0.0.2", "Role": "Replica", "State": "running", "TL": 2, "Lag in MB": 3},
+  {"Cluster": "tobs", "Member": "tobs-timescaledb-2", "Host": "10.0.0.3", "Role": "Replica", "State": "starting", "TL": "", "Lag in MB": "unknown", "Pending restart": "*"}
+]`
+
+func TestParseMembers(t *testing.T) {
+	members, err := ParseMembers([]byte(listJSON))
+	if err != nil {
+		t.Fatalf("ParseMembers() error = %v", err)
+	}
+	if len(members) != 3 {
+		t.Fatalf("ParseMembers() got %d members, want 3", len(members))
+	}
+
+	leader, err := GetLeader(members)
+	if err != nil {
+		t.Fatalf("GetLeader() error = %v", err)
+	}
+	if leader.Name != "tobs-timescaledb-0" || leader.Timeline != 2 || leader.LagMB != nil {
+		t.Errorf("GetLeader() got = %+v", leader)
+	}
+
+	if members[1].LagMB == nil || *members[1].LagMB != 3 || members[1].Cluster != "tobs" {
+		t.Errorf("ParseMembers() got replica = %+v", members[1])
+	}
+
+	if members[2].LagMB != nil || members[2].Timeline != 0 || !members[2].PendingRestart {
+		t.Errorf("ParseMembers() got unknown lag replica = %+v", members[2])
+	}
+
+	if _, err := GetLeader(members[1:]); err == nil {
+		t.Errorf("GetLeader() expected an error without a leader")
+	}
+}
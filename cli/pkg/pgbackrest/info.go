@@ -0,0 +1,147 @@
+package pgbackrest
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Stanza is a pgBackRest stanza as reported by pgbackrest info --output=json
+type Stanza struct {
+	Name    string      `json:"name"`
+	Status  Status      `json:"status"`
+	DB      []DB        `json:"db"`
+	Archive []Archive   `json:"archive"`
+	Backups []Backup    `json:"backup"`
+	Cipher  string      `json:"cipher"`
+	Repos   []RepoState `json:"repo,omitempty"`
+}
+
+type Status struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type DB struct {
+	ID       int    `json:"id"`
+	SystemID int64  `json:"system-id"`
+	Version  string `json:"version"`
+}
+
+// Archive is the WAL archive range of a database
+type Archive struct {
+	ID  string `json:"id"`
+	Min string `json:"min"`
+	Max string `json:"max"`
+}
+
+type RepoState struct {
+	Key    int    `json:"key"`
+	Cipher string `json:"cipher"`
+	Status Status `json:"status"`
+}
+
+type Backup struct {
+	Label     string          `json:"label"`
+	Type      string          `json:"type"`
+	Prior     string          `json:"prior"`
+	Error     bool            `json:"error"`
+	Archive   BackupArchive   `json:"archive"`
+	Info      BackupInfo      `json:"info"`
+	LSN       BackupLSN       `json:"lsn"`
+	Timestamp BackupTimestamp `json:"timestamp"`
+}
+
+// BackupArchive is the range of WAL needed to make the backup consistent
+type BackupArchive struct {
+	Start string `json:"start"`
+	Stop  string `json:"stop"`
+}
+
+type BackupInfo struct {
+	// Size of the database at the time of the backup
+	Size int64 `json:"size"`
+	// Delta is the amount of the database updated by the backup
+	Delta      int64          `json:"delta"`
+	Repository RepositoryInfo `json:"repository"`
+}
+
+type RepositoryInfo struct {
+	Size  int64 `json:"size"`
+	Delta int64 `json:"delta"`
+}
+
+type BackupLSN struct {
+	Start string `json:"start"`
+	Stop  string `json:"stop"`
+}
+
+// BackupTimestamp holds the unix timestamps the backup started and stopped at
+type BackupTimestamp struct {
+	Start int64 `json:"start"`
+	Stop  int64 `json:"stop"`
+}
+
+func (b Backup) StartTime() time.Time {
+	return time.Unix(b.Timestamp.Start, 0).UTC()
+}
+
+func (b Backup) StopTime() time.Time {
+	return time.Unix(b.Timestamp.Stop, 0).UTC()
+}
+
+// ParseInfo parses the output of pgbackrest info --output=json
+func ParseInfo(data []byte) ([]Stanza, error) {
+	var stanzas []Stanza
+	err := json.Unmarshal(data, &stanzas)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse pgbackrest info: %w", err)
+	}
+
+	for _, s := range stanzas {
+		sort.Slice(s.Backups, func(i, j int) bool {
+			return s.Backups[i].Timestamp.Stop < s.Backups[j].Timestamp.Stop
+		})
+	}
+	return stanzas, nil
+}
+
+// GetStanza returns the stanza with the name from the parsed info
+func GetStanza(stanzas []Stanza, name string) (*Stanza, error) {
+	for i := range stanzas {
+		if stanzas[i].Name == name {
+			return &stanzas[i], nil
+		}
+	}
+	return nil, fmt.Errorf("stanza %s not found", name)
+}
+
+// GetBackup returns the backup with the label
+func (s *Stanza) GetBackup(label string) (*Backup, error) {
+	for i := range s.Backups {
+		if s.Backups[i].Label == label {
+			return &s.Backups[i], nil
+		}
+	}
+	return nil, fmt.Errorf("backup %s not found in stanza %s", label, s.Name)
+}
+
+// LatestBackupBefore returns the most recent successful backup which finished
+// before the target time, which is the backup a point in time restore starts from
+func (s *Stanza) LatestBackupBefore(target time.Time) (*Backup, error) {
+	var latest *Backup
+	for i := range s.Backups {
+		b := &s.Backups[i]
+		if b.Error || b.StopTime().After(target) {
+			continue
+		}
+		if latest == nil || b.Timestamp.Stop > latest.Timestamp.Stop {
+			latest = b
+		}
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("no backup of stanza %s finished before %s", s.Name, target.Format(time.RFC3339))
+	}
+	return latest, nil
+}
@@ -0,0 +1,111 @@
+package pgbackrest
+
+import (
+	"testing"
+	"time"
+)
+
+const infoJSON = `[
+  {
+    "archive": [{"database": {"id": 1, "repo-key": 1}, "id": "12-1", "max": "000000010000000000000009", "min": "000000010000000000000001"}],
+    "backup": [
+      {
+        "archive": {"start": "000000010000000000000007", "stop": "000000010000000000000007"},
+        "backrest": {"format": 5, "version": "2.33"},
+        "database": {"id": 1, "repo-key": 1},
+        "error": false,
+        "info": {"delta": 2048, "repository": {"delta": 512, "size": 512}, "size": 4096},
+        "label": "20211020-110000F_20211020-120000I",
+        "lsn": {"start": "0/7000028", "stop": "0/7000100"},
+        "prior": "20211020-110000F",
+        "reference": ["20211020-110000F"],
+        "timestamp": {"start": 1634731200, "stop": 1634731260},
+        "type": "incr"
+      },
+      {
+        "archive": {"start": "000000010000000000000003", "stop": "000000010000000000000003"},
+        "backrest": {"format": 5, "version": "2.33"},
+        "database": {"id": 1, "repo-key": 1},
+        "error": false,
+        "info": {"delta": 4096, "repository": {"delta": 1024, "size": 1024}, "size": 4096},
+        "label": "20211020-110000F",
+        "lsn": {"start": "0/3000028", "stop": "0/3000100"},
+        "prior": null,
+        "reference": null,
+        "timestamp": {"start": 1634727600, "stop": 1634727700},
+        "type": "full"
+      }
+    ],
+    "cipher": "none",
+    "db": [{"id": 1, "repo-key": 1, "system-id": 7021029510512345678, "version": "12"}],
+    "name": "poddb",
+    "repo": [{"cipher": "none", "key": 1, "status": {"code": 0, "message": "ok"}}],
+    "status": {"code": 0, "lock": {"backup": {"held": false}}, "message": "ok"}
+  }
+]`
+
+func TestParseInfo(t *testing.T) {
+	stanzas, err := ParseInfo([]byte(infoJSON))
+	if err != nil {
+		t.Fatalf("ParseInfo() error = %v", err)
+	}
+
+	s, err := GetStanza(stanzas, "poddb")
+	if err != nil {
+		t.Fatalf("GetStanza() error = %v", err)
+	}
+
+	if s.Status.Message != "ok" || s.DB[0].Version != "12" || s.Archive[0].Max != "000000010000000000000009" {
+		t.Errorf("ParseInfo() got = %+v", s)
+	}
+
+	// backups are sorted by the time they finished
+	if len(s.Backups) != 2 || s.Backups[0].Label != "20211020-110000F" {
+		t.Fatalf("ParseInfo() got backups = %+v", s.Backups)
+	}
+
+	b, err := s.GetBackup("20211020-110000F_20211020-120000I")
+	if err != nil {
+		t.Fatalf("GetBackup() error = %v", err)
+	}
+	if b.Type != "incr" || b.Prior != "20211020-110000F" || b.Info.Repository.Size != 512 {
+		t.Errorf("GetBackup() got = %+v", b)
+	}
+	if got, want := b.StopTime(), time.Date(2021, 10, 20, 12, 1, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Backup.StopTime() got = %v, want %v", got, want)
+	}
+
+	if _, err := GetStanza(stanzas, "other"); err == nil {
+		t.Errorf("GetStanza() expected an error for a missing stanza")
+	}
+}
+
+func TestLatestBackupBefore(t *testing.T) {
+	stanzas, err := ParseInfo([]byte(infoJSON))
+	if err != nil {
+		t.Fatalf("ParseInfo() error = %v", err)
+	}
+	s := &stanzas[0]
+
+	tests := []struct {
+		name    string
+		target  time.Time
+		want    string
+		wantErr bool
+	}{
+		{name: "after the last backup", target: time.Date(2021, 10, 20, 13, 0, 0, 0, time.UTC), want: "20211020-110000F_20211020-120000I"},
+		{name: "between the backups", target: time.Date(2021, 10, 20, 11, 30, 0, 0, time.UTC), want: "20211020-110000F"},
+		{name: "during the first backup", target: time.Date(2021, 10, 20, 11, 0, 30, 0, time.UTC), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.LatestBackupBefore(tt.target)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LatestBackupBefore() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.Label != tt.want {
+				t.Errorf("LatestBackupBefore() got = %v, want %v", got.Label, tt.want)
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"strconv"
 
 	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/timescale/tobs/cli/pkg/helm"
 	"github.com/timescale/tobs/cli/pkg/k8s"
@@ -47,12 +48,20 @@ func (d *DBDetails) OpenConnectionToDB() (*pgxpool.Pool, error) {
 
 	secs := promscaleSecret.Data
 
-	var port, host, sslmode, dbURI, pass string
+	var port, host, sslmode, dbURI string
 	port = string(secs["PROM_DB_PORT"])
 	host = string(secs["PROMSCALE_DB_HOST"])
 	sslmode = string(secs["PROMSCALE_DB_SSL_MODE"])
 	dbURI = string(secs["PROMSCALE_DB_URI"])
-	pass = string(secs["PROMSCALE_DB_PASSWORD"])
+
+	promscaleUser, err := d.promscaleUser(dbURI)
+	if err != nil {
+		return nil, err
+	}
+	pass, err := connectionPassword(d.User, d.Password, promscaleUser, string(secs["PROMSCALE_DB_PASSWORD"]))
+	if err != nil {
+		return nil, err
+	}
 
 	tsdbPods, err := k8sClient.KubeGetPods(d.Namespace, map[string]string{"release": d.ReleaseName, "role": "master"})
 	if err != nil {
@@ -83,7 +92,11 @@ func (d *DBDetails) OpenConnectionToDB() (*pgxpool.Pool, error) {
 		}
 	} else {
 		if dbURI != "" {
-			pool, err = pgxpool.Connect(context.Background(), dbURI)
+			config, err := configForURI(dbURI, d.User, d.DBName, pass)
+			if err != nil {
+				return nil, err
+			}
+			pool, err = pgxpool.ConnectConfig(context.Background(), config)
 			if err != nil {
 				return nil, err
 			}
@@ -110,6 +123,69 @@ func (d *DBDetails) OpenConnectionToDB() (*pgxpool.Pool, error) {
 	return pool, nil
 }
 
+// PromscaleUser returns the database user Promscale connects as
+func (d *DBDetails) PromscaleUser() (string, error) {
+	k8sClient := k8s.NewClient()
+	secName, err := GetPromscaleSecretName(d.ReleaseName, d.Namespace)
+	if err != nil {
+		return "", err
+	}
+	promscaleSecret, err := k8sClient.KubeGetSecret(d.Namespace, secName)
+	if err != nil {
+		return "", err
+	}
+	return d.promscaleUser(string(promscaleSecret.Data["PROMSCALE_DB_URI"]))
+}
+
+// promscaleUser returns the user the password in the Promscale secret is of
+func (d *DBDetails) promscaleUser(dbURI string) (string, error) {
+	if dbURI != "" {
+		config, err := pgxpool.ParseConfig(dbURI)
+		if err != nil {
+			return "", err
+		}
+		return config.ConnConfig.User, nil
+	}
+
+	helmClient := helm.NewClient(d.Namespace)
+	defer helmClient.Close()
+	user, err := helmClient.ExportValuesFieldFromRelease(d.ReleaseName, []string{"promscale", "connection", "user"})
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprint(user), nil
+}
+
+// connectionPassword returns the password to connect as user with. The Promscale secret only
+// has the password of the Promscale user, so any other user needs its own password.
+func connectionPassword(user, password, promscaleUser, promscalePassword string) (string, error) {
+	if password != "" {
+		return password, nil
+	}
+	if user != "" && user != promscaleUser {
+		return "", fmt.Errorf("no password provided for user %s", user)
+	}
+	return promscalePassword, nil
+}
+
+// configForURI parses the connection URI with the user, database and password overridden where they're set
+func configForURI(dbURI, user, dbname, password string) (*pgxpool.Config, error) {
+	config, err := pgxpool.ParseConfig(dbURI)
+	if err != nil {
+		return nil, err
+	}
+	if user != "" {
+		config.ConnConfig.User = user
+	}
+	if dbname != "" {
+		config.ConnConfig.Database = dbname
+	}
+	if password != "" {
+		config.ConnConfig.Password = password
+	}
+	return config, nil
+}
+
 func UpdatePasswordInDBURI(dburi, newpass string) (string, error) {
 	db, err := pgxpool.ParseConfig(dburi)
 	if err != nil {
@@ -179,3 +255,21 @@ func GetPromscaleSecretName(releaseName, namespace string) (string, error) {
 
 	return secretName, nil
 }
+
+// Querier is implemented by pgx connections, pools and transactions
+type Querier interface {
+	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+// AlterUserPassword changes the password of the user. ALTER ROLE doesn't take
+// parameters so the server quotes the user and password with format instead.
+func AlterUserPassword(ctx context.Context, q Querier, user, password string) error {
+	var stmt string
+	err := q.QueryRow(ctx, "SELECT format('ALTER ROLE %I WITH PASSWORD %L', $1::TEXT, $2::TEXT)", user, password).Scan(&stmt)
+	if err != nil {
+		return err
+	}
+	_, err = q.Exec(ctx, stmt)
+	return err
+}
@@ -0,0 +1,117 @@
+package pgconn
+
+import "strings"
+
+// SplitStatements splits a SQL script into its statements on the semicolons outside of
+// quoted strings and identifiers, dollar-quoted strings and comments. Statements with
+// only comments and whitespace are dropped.
+func SplitStatements(script string) []string {
+	var statements []string
+	start := 0
+	hasCode := false
+	add := func(end int) {
+		if hasCode {
+			statements = append(statements, strings.TrimSpace(script[start:end]))
+		}
+		start = end + 1
+		hasCode = false
+	}
+
+	for i := 0; i < len(script); {
+		c := script[i]
+		switch {
+		case c == '\'' || c == '"':
+			i = skipQuoted(script, i)
+			hasCode = true
+		case strings.HasPrefix(script[i:], "--"):
+			end := strings.IndexByte(script[i:], '\n')
+			if end < 0 {
+				i = len(script)
+			} else {
+				i += end + 1
+			}
+		case strings.HasPrefix(script[i:], "/*"):
+			i = skipBlockComment(script, i)
+		case c == '$' && dollarTag(script, i) != "":
+			tag := dollarTag(script, i)
+			end := strings.Index(script[i+len(tag):], tag)
+			if end < 0 {
+				i = len(script)
+			} else {
+				i += len(tag) + end + len(tag)
+			}
+			hasCode = true
+		case c == ';':
+			add(i)
+			i++
+		default:
+			if c != ' ' && c != '\t' && c != '\n' && c != '\r' {
+				hasCode = true
+			}
+			i++
+		}
+	}
+	add(len(script))
+	return statements
+}
+
+// skipQuoted returns the position after the string or identifier starting at i.
+// Quotes are escaped by doubling them, and with a backslash in E'...' strings.
+func skipQuoted(script string, i int) int {
+	quote := script[i]
+	escapes := quote == '\'' && i > 0 && (script[i-1] == 'E' || script[i-1] == 'e') && (i < 2 || !isIdentChar(script[i-2]))
+	for j := i + 1; j < len(script); j++ {
+		switch {
+		case escapes && script[j] == '\\':
+			j++
+		case script[j] == quote:
+			if j+1 < len(script) && script[j+1] == quote {
+				j++
+				continue
+			}
+			return j + 1
+		}
+	}
+	return len(script)
+}
+
+// skipBlockComment returns the position after the comment starting at i, block comments nest
+func skipBlockComment(script string, i int) int {
+	depth := 0
+	for j := i; j < len(script)-1; j++ {
+		switch {
+		case script[j] == '/' && script[j+1] == '*':
+			depth++
+			j++
+		case script[j] == '*' && script[j+1] == '/':
+			depth--
+			j++
+			if depth == 0 {
+				return j + 1
+			}
+		}
+	}
+	return len(script)
+}
+
+// dollarTag returns the $tag$ starting at i, or an empty string when there is none.
+// Parameters such as $1 and identifiers containing $ aren't tags.
+func dollarTag(script string, i int) string {
+	if i > 0 && isIdentChar(script[i-1]) {
+		return ""
+	}
+	for j := i + 1; j < len(script); j++ {
+		c := script[j]
+		if c == '$' {
+			return script[i : j+1]
+		}
+		if !isIdentChar(c) || (j == i+1 && c >= '0' && c <= '9') {
+			return ""
+		}
+	}
+	return ""
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c >= 0x80
+}
@@ -0,0 +1,52 @@
+package pgconn
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{
+			name:   "single statement without semicolon",
+			script: "SELECT 1",
+			want:   []string{"SELECT 1"},
+		},
+		{
+			name:   "multiple statements",
+			script: "CREATE TABLE t (a int);\nINSERT INTO t VALUES (1);\n\nSELECT * FROM t;\n",
+			want:   []string{"CREATE TABLE t (a int)", "INSERT INTO t VALUES (1)", "SELECT * FROM t"},
+		},
+		{
+			name:   "semicolons in strings and identifiers",
+			script: `SELECT 'a;b', 'it''s;', "col;1" FROM t; SELECT E'\';'`,
+			want:   []string{`SELECT 'a;b', 'it''s;', "col;1" FROM t`, `SELECT E'\';'`},
+		},
+		{
+			name:   "comments",
+			script: "-- setup; done\nSELECT 1; /* a; /* nested; */ b; */ SELECT 2;\n-- trailing; comment\n",
+			want:   []string{"-- setup; done\nSELECT 1", "/* a; /* nested; */ b; */ SELECT 2"},
+		},
+		{
+			name:   "dollar quoting",
+			script: "CREATE FUNCTION f() RETURNS int AS $fn$ BEGIN RETURN 1; END; $fn$ LANGUAGE plpgsql; SELECT $$a;b$$, $1;",
+			want:   []string{"CREATE FUNCTION f() RETURNS int AS $fn$ BEGIN RETURN 1; END; $fn$ LANGUAGE plpgsql", "SELECT $$a;b$$, $1"},
+		},
+		{
+			name:   "only comments",
+			script: "-- nothing to run\n;;",
+			want:   nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SplitStatements(tt.script); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitStatements() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	SetDefaultRetention     = "set-default-retention"
+	SetDefaultChunkInterval = "set-default-chunk-interval"
+	SetRetention            = "set-retention"
+	ResetRetention          = "reset-retention"
+	SetChunkInterval        = "set-chunk-interval"
+	ResetChunkInterval      = "reset-chunk-interval"
+)
+
+// Settings are the storage settings which can be configured by a policy
+type Settings struct {
+	RetentionDays *int64 `json:"retentionDays,omitempty"`
+	ChunkInterval string `json:"chunkInterval,omitempty"`
+
+	chunkInterval time.Duration
+}
+
+// Override applies settings to a single metric selected by name
+// or to all the metrics whose whole name matches the regex.
+type Override struct {
+	Name  string `json:"name,omitempty"`
+	Regex string `json:"regex,omitempty"`
+	Settings
+
+	re *regexp.Regexp
+}
+
+// Policy is the desired retention and chunk interval of all the metrics
+type Policy struct {
+	Default   Settings   `json:"default"`
+	Overrides []Override `json:"overrides"`
+}
+
+// MetricState is the current storage settings of a metric. The custom
+// flags are set when the metric doesn't use the default value.
+type MetricState struct {
+	Name                string
+	RetentionDays       int64
+	CustomRetention     bool
+	ChunkInterval       time.Duration
+	CustomChunkInterval bool
+}
+
+// State is the current storage settings of the database
+type State struct {
+	DefaultRetentionDays int64
+	DefaultChunkInterval time.Duration
+	Metrics              []MetricState
+}
+
+// Change is a single step needed to reconcile the database with a policy
+type Change struct {
+	Action string
+	Metric string
+	// RetentionDays or ChunkInterval is set depending on the action
+	RetentionDays int64
+	ChunkInterval time.Duration
+}
+
+func (c Change) String() string {
+	switch c.Action {
+	case SetDefaultRetention:
+		return fmt.Sprintf("set default retention period to %d days", c.RetentionDays)
+	case SetDefaultChunkInterval:
+		return fmt.Sprintf("set default chunk interval to %v", c.ChunkInterval)
+	case SetRetention:
+		return fmt.Sprintf("set retention period of %v to %d days", c.Metric, c.RetentionDays)
+	case ResetRetention:
+		return fmt.Sprintf("reset retention period of %v to the default", c.Metric)
+	case SetChunkInterval:
+		return fmt.Sprintf("set chunk interval of %v to %v", c.Metric, c.ChunkInterval)
+	case ResetChunkInterval:
+		return fmt.Sprintf("reset chunk interval of %v to the default", c.Metric)
+	}
+	return c.Action
+}
+
+// Load reads and validates a policy file
+func Load(file string) (*Policy, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file %s: %w", file, err)
+	}
+	p, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid policy file %s: %w", file, err)
+	}
+	return p, nil
+}
+
+// Parse parses and validates a YAML policy
+func Parse(data []byte) (*Policy, error) {
+	p := &Policy{}
+	err := yaml.UnmarshalStrict(data, p)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = p.Default.validate(); err != nil {
+		return nil, fmt.Errorf("default: %w", err)
+	}
+
+	for i := range p.Overrides {
+		o := &p.Overrides[i]
+		if (o.Name == "") == (o.Regex == "") {
+			return nil, fmt.Errorf("override %d: exactly one of name or regex must be set", i)
+		}
+		if o.RetentionDays == nil && o.ChunkInterval == "" {
+			return nil, fmt.Errorf("override %d: at least one of retentionDays or chunkInterval must be set", i)
+		}
+		if err = o.validate(); err != nil {
+			return nil, fmt.Errorf("override %d: %w", i, err)
+		}
+		if o.Regex != "" {
+			o.re, err = regexp.Compile("^(?:" + o.Regex + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("override %d: %w", i, err)
+			}
+		}
+	}
+
+	return p, nil
+}
+
+func (s *Settings) validate() error {
+	if s.RetentionDays != nil && *s.RetentionDays < 1 {
+		return errors.New("retentionDays must be at least 1")
+	}
+	if s.ChunkInterval != "" {
+		var err error
+		s.chunkInterval, err = time.ParseDuration(s.ChunkInterval)
+		if err != nil {
+			return err
+		}
+		if s.chunkInterval.Minutes() < 1.0 {
+			return errors.New("chunkInterval must be at least 1 minute")
+		}
+	}
+	return nil
+}
+
+func (o *Override) matches(metric string) bool {
+	if o.re != nil {
+		return o.re.MatchString(metric)
+	}
+	return o.Name == metric
+}
+
+// override returns the first override setting the field picked by has.
+// Overrides by name take precedence over the ones by regex.
+func (p *Policy) override(metric string, has func(o *Override) bool) *Override {
+	var byRegex *Override
+	for i := range p.Overrides {
+		o := &p.Overrides[i]
+		if !has(o) || !o.matches(metric) {
+			continue
+		}
+		if o.re == nil {
+			return o
+		}
+		if byRegex == nil {
+			byRegex = o
+		}
+	}
+	return byRegex
+}
+
+// Plan returns the changes needed to reconcile the state with the policy.
+// Metrics without a matching override are reset to the default value.
+func (p *Policy) Plan(s *State) []Change {
+	var changes []Change
+
+	if p.Default.RetentionDays != nil && *p.Default.RetentionDays != s.DefaultRetentionDays {
+		changes = append(changes, Change{Action: SetDefaultRetention, RetentionDays: *p.Default.RetentionDays})
+	}
+	if p.Default.ChunkInterval != "" && p.Default.chunkInterval != s.DefaultChunkInterval {
+		changes = append(changes, Change{Action: SetDefaultChunkInterval, ChunkInterval: p.Default.chunkInterval})
+	}
+
+	metrics := make([]MetricState, len(s.Metrics))
+	copy(metrics, s.Metrics)
+	sort.Slice(metrics, func(i, j int) bool {
+		return metrics[i].Name < metrics[j].Name
+	})
+
+	for _, m := range metrics {
+		o := p.override(m.Name, func(o *Override) bool { return o.RetentionDays != nil })
+		switch {
+		case o != nil && (!m.CustomRetention || m.RetentionDays != *o.RetentionDays):
+			changes = append(changes, Change{Action: SetRetention, Metric: m.Name, RetentionDays: *o.RetentionDays})
+		case o == nil && m.CustomRetention:
+			changes = append(changes, Change{Action: ResetRetention, Metric: m.Name})
+		}
+
+		o = p.override(m.Name, func(o *Override) bool { return o.ChunkInterval != "" })
+		switch {
+		case o != nil && (!m.CustomChunkInterval || m.ChunkInterval != o.chunkInterval):
+			changes = append(changes, Change{Action: SetChunkInterval, Metric: m.Name, ChunkInterval: o.chunkInterval})
+		case o == nil && m.CustomChunkInterval:
+			changes = append(changes, Change{Action: ResetChunkInterval, Metric: m.Name})
+		}
+	}
+
+	return changes
+}
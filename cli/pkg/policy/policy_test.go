@@ -0,0 +1,154 @@
+package policy
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  string
+		wantErr bool
+	}{
+		{
+			name: "Valid policy",
+			policy: `
+default:
+  retentionDays: 90
+  chunkInterval: 8h
+overrides:
+- name: node_cpu_seconds_total
+  retentionDays: 30
+- regex: "go_.*"
+  chunkInterval: 1h`,
+			wantErr: false,
+		},
+		{
+			name:    "Empty policy",
+			policy:  ``,
+			wantErr: false,
+		},
+		{
+			name: "Unknown field",
+			policy: `
+default:
+  retention: 90`,
+			wantErr: true,
+		},
+		{
+			name: "Override with name and regex",
+			policy: `
+overrides:
+- name: up
+  regex: "u.*"
+  retentionDays: 1`,
+			wantErr: true,
+		},
+		{
+			name: "Override without settings",
+			policy: `
+overrides:
+- name: up`,
+			wantErr: true,
+		},
+		{
+			name: "Invalid regex",
+			policy: `
+overrides:
+- regex: "go_("
+  retentionDays: 1`,
+			wantErr: true,
+		},
+		{
+			name: "Chunk interval below a minute",
+			policy: `
+default:
+  chunkInterval: 30s`,
+			wantErr: true,
+		},
+		{
+			name: "Zero retention",
+			policy: `
+overrides:
+- name: up
+  retentionDays: 0`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.policy))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPolicy_Plan(t *testing.T) {
+	policy := `
+default:
+  retentionDays: 90
+  chunkInterval: 8h
+overrides:
+- regex: "go_.*"
+  retentionDays: 7
+  chunkInterval: 1h
+- name: go_goroutines
+  retentionDays: 30
+- regex: "node_.*"
+  retentionDays: 14`
+
+	state := &State{
+		DefaultRetentionDays: 90,
+		DefaultChunkInterval: 2 * time.Hour,
+		Metrics: []MetricState{
+			// custom values not in the policy are reset
+			{Name: "up", RetentionDays: 10, CustomRetention: true, ChunkInterval: time.Hour, CustomChunkInterval: true},
+			// name override takes precedence over regex for retention only
+			{Name: "go_goroutines", RetentionDays: 90, ChunkInterval: 2 * time.Hour},
+			// already reconciled
+			{Name: "go_threads", RetentionDays: 7, CustomRetention: true, ChunkInterval: time.Hour, CustomChunkInterval: true},
+			// default value equal to the override is still made custom
+			{Name: "node_load1", RetentionDays: 14, ChunkInterval: 2 * time.Hour},
+		},
+	}
+
+	p, err := Parse([]byte(policy))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	got := p.Plan(state)
+	want := []Change{
+		{Action: SetDefaultChunkInterval, ChunkInterval: 8 * time.Hour},
+		{Action: SetRetention, Metric: "go_goroutines", RetentionDays: 30},
+		{Action: SetChunkInterval, Metric: "go_goroutines", ChunkInterval: time.Hour},
+		{Action: SetRetention, Metric: "node_load1", RetentionDays: 14},
+		{Action: ResetRetention, Metric: "up"},
+		{Action: ResetChunkInterval, Metric: "up"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Plan() got = %v, want %v", got, want)
+	}
+}
+
+func TestPolicy_PlanNoChanges(t *testing.T) {
+	p, err := Parse([]byte(`
+default:
+  retentionDays: 90`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	state := &State{
+		DefaultRetentionDays: 90,
+		DefaultChunkInterval: 8 * time.Hour,
+		Metrics:              []MetricState{{Name: "up", RetentionDays: 90, ChunkInterval: 8 * time.Hour}},
+	}
+	if got := p.Plan(state); len(got) != 0 {
+		t.Errorf("Plan() got = %v, want no changes", got)
+	}
+}
@@ -0,0 +1,78 @@
+package upgrade_tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	root "github.com/timescale/tobs/cli/cmd"
+	"github.com/timescale/tobs/cli/cmd/upgrade"
+	"github.com/timescale/tobs/cli/pkg/migration"
+)
+
+// runTobs runs tobs in this process, so the migrations registered by the test are used,
+// and returns what it printed to stdout
+func runTobs(t testing.TB, args ...string) (string, error) {
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+	out := make(chan string)
+	go func() {
+		var b bytes.Buffer
+		_, _ = io.Copy(&b, r)
+		out <- b.String()
+	}()
+
+	t.Logf("Running '%v' in process", "tobs "+strings.Join(args, " "))
+	root.RootCmd.SetArgs(args)
+	err = root.RootCmd.Execute()
+
+	w.Close()
+	os.Stdout = stdout
+	return <-out, err
+}
+
+// testStepwisePlan plans an upgrade from the installed release through an intermediate
+// version. None of the registered migrations needs one, so a migration needing the release
+// at a version after the one the previous migration leaves it at is registered for the test.
+func testStepwisePlan(t *testing.T) {
+	migration.Register(migration.Migration{Name: "stepwise-test", From: "0.9.0", To: "0.10.0"})
+
+	args := []string{"upgrade", "-c", PATH_TO_CHART, "-f", PATH_TO_TEST_VALUES, "--name", RELEASE_NAME, "--namespace", NAMESPACE}
+
+	// the flags of the in-process runs persist, so the run without --plan goes first
+	_, err := runTobs(t, append(args, "-y")...)
+	if err == nil || !strings.Contains(err.Error(), "needs step-wise upgrades through version 0.9.0") {
+		t.Fatalf("upgrade without --stepwise error = %v, want step-wise upgrades through version 0.9.0", err)
+	}
+
+	out, err := runTobs(t, append(args, "--plan", "-o", "json")...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the helm client can print before the plan
+	if i := strings.Index(out, "{"); i > 0 {
+		out = out[i:]
+	}
+	plan := &upgrade.UpgradePlanResult{}
+	err = json.Unmarshal([]byte(out), plan)
+	if err != nil {
+		t.Fatalf("could not parse the upgrade plan %q: %v", out, err)
+	}
+	found := false
+	for _, s := range plan.Steps {
+		if s.Kind == migration.StepUpgrade && s.Description == "helm upgrade to intermediate version 0.9.0" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("upgrade plan steps = %v, want the intermediate upgrade to 0.9.0", plan.Steps)
+	}
+}
@@ -17,6 +17,8 @@ func TestUpgrade(t *testing.T) {
 
 	installTobsRecentRelease()
 
+	testStepwisePlan(t)
+
 	upgradeTobsLatest()
 
 	fmt.Println("Successfully upgraded tobs to latest version")